@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"effective_mobile_2/internal/app/purge"
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/config"
+	"effective_mobile_2/internal/database"
+)
+
+func main() {
+	log.Print("parsing configuration")
+	if err := config.Parse(); err != nil {
+		log.Fatalf("failed to parse config: %v", err)
+	}
+
+	retention := flag.Duration("retention", config.Cfg().Purge.CarRetention, "how long deleted cars are kept")
+	flag.Parse()
+
+	log.Print("connecting database")
+	if err := database.Connect(); err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+
+	log.Print("set upping logger")
+	app_log.Setup(config.Cfg().Logger.Level)
+
+	log.Print("purging deleted cars")
+	if err := purge.Run(*retention); err != nil {
+		log.Fatalf("failed to purge deleted cars: %v", err)
+	}
+	log.Print("purged deleted cars")
+}
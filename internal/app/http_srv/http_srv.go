@@ -2,6 +2,7 @@ package http_srv
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -10,17 +11,31 @@ import (
 	"time"
 
 	_ "effective_mobile_2/docs"
+	"effective_mobile_2/internal/app_audit"
 	"effective_mobile_2/internal/app_log"
 	"effective_mobile_2/internal/config"
 	"effective_mobile_2/internal/database"
+	auditH "effective_mobile_2/internal/handler/http/audit"
 	carH "effective_mobile_2/internal/handler/http/car"
+	healthH "effective_mobile_2/internal/handler/http/health"
+	peopleH "effective_mobile_2/internal/handler/http/people"
+	auditLogGR "effective_mobile_2/internal/repository/gorm/audit_log"
 	carGR "effective_mobile_2/internal/repository/gorm/car"
+	carInfoCacheGR "effective_mobile_2/internal/repository/gorm/car_info_cache"
+	carOwnershipGR "effective_mobile_2/internal/repository/gorm/car_ownership"
 	peopleGR "effective_mobile_2/internal/repository/gorm/people"
+	transactionGR "effective_mobile_2/internal/repository/gorm/transaction"
 	httpSwagger "github.com/swaggo/http-swagger"
 
 	carInfoAR "effective_mobile_2/internal/repository/api/car_info"
+	carInfoBR "effective_mobile_2/internal/repository/breaker/car_info"
+	carInfoCR "effective_mobile_2/internal/repository/cache/car_info"
+	carInfoMR "effective_mobile_2/internal/repository/multi/car_info"
 	//carInfoMock "effective_mobile_2/internal/repository/mock/car_info"
+	auditS "effective_mobile_2/internal/service/audit"
 	carS "effective_mobile_2/internal/service/car"
+	healthS "effective_mobile_2/internal/service/health"
+	peopleS "effective_mobile_2/internal/service/people"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
@@ -34,7 +49,9 @@ func Run() error {
 	router := chi.NewRouter()
 
 	setupMiddleware(router)
-	setupEndpoints(router)
+	if err := setupEndpoints(router); err != nil {
+		return err
+	}
 
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
@@ -68,29 +85,115 @@ func Run() error {
 
 func setupMiddleware(router *chi.Mux) {
 	router.Use(middleware.RequestID)
+	router.Use(app_audit.Middleware)
 	router.Use(middleware.Logger)
 	router.Use()
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.URLFormat)
 }
 
-func setupEndpoints(router *chi.Mux) {
+func setupEndpoints(router *chi.Mux) error {
 
 	router.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("swagger/doc.json"), // The url pointing to API definition
 	))
 
 	carRepository := carGR.New(database.Db().Gorm)
-	carInfoRepository := carInfoAR.New(config.Cfg().Api.CarInfo)
-	//carInfoRepository := carInfoMock.New()
+	carInfoProviders := make([]carInfoMR.Provider, 0, len(config.Cfg().Api.Providers))
+	for _, provider := range config.Cfg().Api.Providers {
+		carInfoAPIRepository, err := carInfoAR.New(provider.Url, carInfoAR.Options{
+			Timeout:    config.Cfg().Api.CarInfoTimeout,
+			Retries:    config.Cfg().Api.CarInfoRetries,
+			Backoff:    config.Cfg().Api.CarInfoBackoff,
+			MaxBackoff: config.Cfg().Api.CarInfoMaxBackoff,
+			Path:       provider.Path,
+			Auth:       carInfoAR.Auth(provider.Auth),
+			Mapping:    provider.Mapping,
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", provider.Name, err)
+		}
+		//carInfoAPIRepository := carInfoMock.New()
+		carInfoBreakerRepository := carInfoBR.New(carInfoAPIRepository, carInfoBR.Options{
+			Failures:    config.Cfg().Api.CarInfoBreakerFailures,
+			OpenTimeout: config.Cfg().Api.CarInfoBreakerOpenTimeout,
+			Probes:      config.Cfg().Api.CarInfoBreakerProbes,
+		})
+		carInfoProviders = append(carInfoProviders, carInfoMR.Provider{
+			Name:       provider.Name,
+			Priority:   provider.Priority,
+			Fields:     provider.Fields,
+			Repository: carInfoBreakerRepository,
+		})
+	}
+	carInfoMultiRepository := carInfoMR.New(carInfoProviders, config.Cfg().Api.CarInfoMerge)
+	carInfoCacheOptions := carInfoCR.Options{
+		TTL:         config.Cfg().Api.CarInfoCacheTTL,
+		NotFoundTTL: config.Cfg().Api.CarInfoCacheNotFoundTTL,
+	}
+	var carInfoRepository *carInfoCR.Repository
+	switch config.Cfg().Api.CarInfoCacheStore {
+	case "memory":
+		carInfoCacheLRU := carInfoCR.NewLRU(config.Cfg().Api.CarInfoCacheSize)
+		carInfoRepository = carInfoCR.New(carInfoMultiRepository, carInfoCacheLRU, carInfoCacheOptions)
+	case "postgres":
+		carInfoCacheRepository := carInfoCacheGR.New(database.Db().Gorm)
+		carInfoRepository = carInfoCR.New(carInfoMultiRepository, carInfoCacheRepository, carInfoCacheOptions)
+	default:
+		return fmt.Errorf("unknown car info cache store: %s", config.Cfg().Api.CarInfoCacheStore)
+	}
 	peopleRepository := peopleGR.New(database.Db().Gorm)
-
-	carService := carS.New(carRepository, carInfoRepository, peopleRepository)
+	carOwnershipRepository := carOwnershipGR.New(database.Db().Gorm)
+	auditLogRepository := auditLogGR.New(database.Db().Gorm)
+	transactionManager := transactionGR.New(database.Db().Gorm)
+
+	carService := carS.New(
+		carRepository,
+		carInfoRepository,
+		peopleRepository,
+		carOwnershipRepository,
+		auditLogRepository,
+		transactionManager,
+		config.Cfg().Api.CarInfoConcurrency,
+		config.Cfg().Http.IfMatchRequired,
+	)
+	peopleService := peopleS.New(
+		peopleRepository,
+		carRepository,
+		carOwnershipRepository,
+		auditLogRepository,
+		transactionManager,
+	)
+	auditService := auditS.New(auditLogRepository)
+	healthService := healthS.New(carInfoMultiRepository)
 
 	carHandler := carH.New(carService)
+	peopleHandler := peopleH.New(peopleService)
+	auditHandler := auditH.New(auditService)
+	healthHandler := healthH.New(healthService)
 
 	router.Get("/api/cars", carHandler.Index())
+	router.Get("/api/cars/{id}", carHandler.Show())
 	router.Post("/api/cars", carHandler.Store())
 	router.Patch("/api/cars/{id}", carHandler.Update())
 	router.Delete("/api/cars/{id}", carHandler.Delete())
+	router.Post("/api/cars/{id}/restore", carHandler.Restore())
+	router.Post("/api/cars/{id}/transfer", carHandler.Transfer())
+	router.Get("/api/cars/{id}/history", carHandler.History())
+	router.Get("/api/cars/{id}/owner", carHandler.Owner())
+	router.Get("/api/cars/{id}/owners", carHandler.Owners())
+
+	router.Get("/api/people", peopleHandler.Index())
+	router.Get("/api/people/duplicates", peopleHandler.Duplicates())
+	router.Get("/api/people/{id}", peopleHandler.Show())
+	router.Post("/api/people", peopleHandler.Store())
+	router.Patch("/api/people/{id}", peopleHandler.Update())
+	router.Delete("/api/people/{id}", peopleHandler.Delete())
+	router.Post("/api/people/{id}/merge", peopleHandler.Merge())
+
+	router.Get("/api/audit", auditHandler.Index())
+
+	router.Get("/api/health", healthHandler.Show())
+
+	return nil
 }
@@ -0,0 +1,64 @@
+package purge
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"effective_mobile_2/internal/app_audit"
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/database"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+	auditLogGR "effective_mobile_2/internal/repository/gorm/audit_log"
+	carGR "effective_mobile_2/internal/repository/gorm/car"
+	"effective_mobile_2/internal/repository/gorm/transaction"
+)
+
+// actor names the purge in the audit log.
+const actor = "purge"
+
+// Run removes for good the cars deleted longer than retention ago,
+// recording each of them in the audit log.
+func Run(retention time.Duration) error {
+	const op = "app.purge.Run"
+
+	log := app_log.Logger().With(slog.String("op", op), slog.Duration("retention", retention))
+
+	log.Info("purging deleted cars")
+
+	carRepository := carGR.New(database.Db().Gorm)
+	auditLogRepository := auditLogGR.New(database.Db().Gorm)
+	transactor := transaction.New(database.Db().Gorm)
+
+	var cars *[]model.Car
+	ctx := app_audit.WithActor(context.Background(), actor)
+	err := transactor.Transaction(ctx, func(ctx context.Context) error {
+		qry := query.CarPurge{DeletedBefore: time.Now().Add(-retention)}
+		var err error
+		cars, err = carRepository.Purge(ctx, &qry)
+		if err != nil {
+			return err
+		}
+		for i := range *cars {
+			qryAudit := query.AuditLogCreate{
+				EntityType: model.AuditEntityCar,
+				EntityID:   (*cars)[i].ID,
+				Action:     model.AuditActionPurge,
+				Before:     &(*cars)[i],
+				Actor:      app_audit.Actor(ctx),
+			}
+			if _, err = auditLogRepository.Create(ctx, &qryAudit); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("purged deleted cars", slog.Int("count", len(*cars)))
+
+	return nil
+}
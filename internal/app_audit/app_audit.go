@@ -0,0 +1,69 @@
+package app_audit
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ActorHeader names the request header identifying who makes the changes.
+// Its value is asserted by the client and not authenticated, so the audit
+// log records it as a hint of who made a change, not a proof.
+const ActorHeader = "X-Actor"
+
+// maxActorLength bounds the actor in runes, as the audit log column does.
+const maxActorLength = 100
+
+const anonymous = "anonymous"
+
+type actorKey struct{}
+
+// Middleware puts the sanitized ActorHeader value of the request into its
+// context.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(WithActor(r.Context(), r.Header.Get(ActorHeader))))
+	})
+}
+
+// WithActor returns a copy of ctx in which actor makes the changes. Control
+// and other non printable characters are dropped and the rest is cut to
+// maxActorLength, an actor left empty is anonymous.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, sanitize(actor))
+}
+
+func sanitize(actor string) string {
+	runes := make([]rune, 0, min(len(actor), maxActorLength))
+	for _, r := range strings.TrimSpace(actor) {
+		if r == utf8.RuneError || !unicode.IsPrint(r) {
+			continue
+		}
+		if runes = append(runes, r); len(runes) == maxActorLength {
+			break
+		}
+	}
+	if sanitized := strings.TrimSpace(string(runes)); sanitized != "" {
+		return sanitized
+	}
+
+	return anonymous
+}
+
+// Actor returns who makes the changes of ctx.
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok {
+		return actor
+	}
+
+	return anonymous
+}
+
+// RequestID returns the ID chi assigned to the request of ctx.
+func RequestID(ctx context.Context) string {
+	return middleware.GetReqID(ctx)
+}
@@ -1,9 +1,72 @@
 package app_error
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrNotFound          = errors.New("not found")
 	ErrDatabase          = errors.New("database error")
 	ErrHTTPRequestFailed = errors.New("http request failed")
+	ErrInvalidArgument   = errors.New("invalid argument")
+	ErrConflict          = errors.New("conflict")
+	ErrUnavailable       = errors.New("service unavailable")
+	// ErrPreconditionFailed reports a change based on an outdated version.
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrPreconditionRequired reports a change missing the version it is based on.
+	ErrPreconditionRequired = errors.New("precondition required")
 )
+
+// Code returns a stable machine readable code of the sentinel err wraps.
+func Code(err error) string {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrDatabase):
+		return "database_error"
+	case errors.Is(err, ErrHTTPRequestFailed):
+		return "http_request_failed"
+	case errors.Is(err, ErrInvalidArgument):
+		return "invalid_argument"
+	case errors.Is(err, ErrConflict):
+		return "conflict"
+	case errors.Is(err, ErrUnavailable):
+		return "unavailable"
+	case errors.Is(err, ErrPreconditionFailed):
+		return "precondition_failed"
+	case errors.Is(err, ErrPreconditionRequired):
+		return "precondition_required"
+	default:
+		return "internal_error"
+	}
+}
+
+type retryAfterError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+// WithRetryAfter annotates err with how long to wait before retrying.
+func WithRetryAfter(err error, after time.Duration) error {
+	return &retryAfterError{err: err, after: after}
+}
+
+// RetryAfter returns how long to wait before retrying what failed with err,
+// when err says so.
+func RetryAfter(err error) (time.Duration, bool) {
+	var retryAfter *retryAfterError
+	if errors.As(err, &retryAfter) {
+		return retryAfter.after, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,100 @@
+package app_mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Field selects a value from a decoded JSON document with a JSONPath-like
+// Path and passes it through Transforms in order. In JSON it is given
+// either as an object or as the path alone. A field is compiled once before
+// its values are read, which reports a bad path or transform.
+type Field struct {
+	Path       string   `json:"path"`
+	Transforms []string `json:"transforms"`
+
+	compiled   bool
+	steps      []step
+	transforms []transform
+}
+
+func (f *Field) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*f = Field{Path: path}
+		return nil
+	}
+
+	type field Field
+	return json.Unmarshal(data, (*field)(f))
+}
+
+// Compile parses the path and the transforms of the field.
+func (f *Field) Compile() error {
+	steps, err := parsePath(f.Path)
+	if err != nil {
+		return err
+	}
+	transforms := make([]transform, len(f.Transforms))
+	for i, spec := range f.Transforms {
+		if transforms[i], err = parseTransform(spec); err != nil {
+			return err
+		}
+	}
+	f.compiled, f.steps, f.transforms = true, steps, transforms
+
+	return nil
+}
+
+// Value returns the transformed value of the first match of the path that
+// is not null, or nil when nothing matches. Numbers of the document may be
+// float64 or json.Number, the latter keeping large integers exact.
+func (f Field) Value(document any) (any, error) {
+	if !f.compiled {
+		return nil, invalid("field is not compiled", f.Path)
+	}
+
+	var value any
+	for _, match := range evaluate(f.steps, document) {
+		if match != nil {
+			value = match
+			break
+		}
+	}
+	var err error
+	for _, transform := range f.transforms {
+		if value, err = transform(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return value, nil
+}
+
+// Mapping maps target fields to where their values come from.
+type Mapping map[string]Field
+
+// Compile compiles every field of the mapping, reporting the target of the
+// first one that fails.
+func (m Mapping) Compile() error {
+	targets := make([]string, 0, len(m))
+	for target := range m {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	for _, target := range targets {
+		field := m[target]
+		if err := field.Compile(); err != nil {
+			return fmt.Errorf("%s: %w", target, err)
+		}
+		m[target] = field
+	}
+
+	return nil
+}
+
+func invalid(message string, value any) error {
+	return fmt.Errorf("%s - %v", message, value)
+}
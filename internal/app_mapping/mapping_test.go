@@ -0,0 +1,211 @@
+package app_mapping_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"effective_mobile_2/internal/app_mapping"
+)
+
+const documentJSON = `{
+	"vehicle": {"brand": "lada", "model": " vesta ", "year": "05/98"},
+	"reg info": {"year": 2002},
+	"persons": [
+		{"role": "seller", "name": "Petr Petrov", "id": 9007199254740992},
+		{"role": "owner", "name": "Ivan Ivanovich Ivanov", "id": 9007199254740993, "age": 30}
+	],
+	"documents": [{"year": null}, {"year": 2010}],
+	"registered": 1577836800,
+	"registeredMs": 1577836800000
+}`
+
+// decode reads data the way the car info providers are read, numbers
+// kept as json.Number.
+func decode(t *testing.T, data string) any {
+	t.Helper()
+
+	var value any
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		t.Fatalf("failed to decode %s: %v", data, err)
+	}
+
+	return value
+}
+
+func value(t *testing.T, field app_mapping.Field, document any) (any, error) {
+	t.Helper()
+
+	if err := field.Compile(); err != nil {
+		t.Fatalf("failed to compile %+v: %v", field, err)
+	}
+
+	return field.Value(document)
+}
+
+func TestFieldValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		transforms []string
+		want       any
+	}{
+		{"key", "$.vehicle.brand", nil, "lada"},
+		{"key without root", "vehicle.brand", nil, "lada"},
+		{"quoted key", "$['reg info'].year", nil, json.Number("2002")},
+		{"index", "$.persons[0].name", nil, "Petr Petrov"},
+		{"negative index", "$.persons[-1].name", nil, "Ivan Ivanovich Ivanov"},
+		{"numeric key", "persons.1.name", nil, "Ivan Ivanovich Ivanov"},
+		{"index out of range", "$.persons[2].name", nil, nil},
+		{"missing", "$.vehicle.color", nil, nil},
+		{"wildcard skips nulls", "$.documents[*].year", nil, json.Number("2010")},
+		{"wildcard over object in key order", "$.vehicle.*", nil, "lada"},
+		{"filter equal", "$.persons[?(@.role == 'owner')].name", nil, "Ivan Ivanovich Ivanov"},
+		{"filter not equal", "$.persons[?(@.role != 'owner')].name", nil, "Petr Petrov"},
+		{"filter JSON string", `$.persons[?(@.role == "seller")].name`, nil, "Petr Petrov"},
+		{"filter large integer", "$.persons[?(@.id == 9007199254740993)].name", nil, "Ivan Ivanovich Ivanov"},
+		{"filter number", "$.persons[?(@.age == 30.0)].name", nil, "Ivan Ivanovich Ivanov"},
+		{"filter no match", "$.persons[?(@.role == 'driver')].name", nil, nil},
+		{"large integer as string", "$.persons[1].id", []string{"string"}, "9007199254740993"},
+		{"trim and title", "$.vehicle.model", []string{"trim", "title"}, "Vesta"},
+		{"upper", "$.vehicle.brand", []string{"upper"}, "LADA"},
+		{"first word", "$.persons[1].name", []string{"word:0"}, "Ivan"},
+		{"middle word", "$.persons[1].name", []string{"word:1"}, "Ivanovich"},
+		{"last word", "$.persons[1].name", []string{"word:-1"}, "Ivanov"},
+		{"word from the end", "$.persons[1].name", []string{"word:-3"}, "Ivan"},
+		{"word out of range", "$.persons[1].name", []string{"word:-4"}, ""},
+		{"default when missing", "$.vehicle.color", []string{"default:white"}, "white"},
+		{"default when present", "$.vehicle.brand", []string{"default:white"}, "lada"},
+		{"year from date", "$.vehicle.year", []string{"year"}, 1998},
+		{"year from number", "$['reg info'].year", []string{"year"}, 2002},
+		{"year from age", "$.persons[1].age", []string{"age"}, time.Now().Year() - 30},
+		{"year from unix", "$.registered", []string{"unix"}, 2020},
+		{"year from unix ms", "$.registeredMs", []string{"unixMs"}, 2020},
+		{"number", "$['reg info'].year", []string{"number"}, 2002.0},
+	}
+
+	document := decode(t, documentJSON)
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := value(t, app_mapping.Field{Path: tt.path, Transforms: tt.transforms}, document)
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYear(t *testing.T) {
+	now := time.Now().Year()
+
+	tests := []struct {
+		name    string
+		value   string
+		want    any
+		wantErr bool
+	}{
+		{"number", `1998`, 1998, false},
+		{"string", `"1998"`, 1998, false},
+		{"date", `"2010-05-01"`, 2010, false},
+		{"date with 2-digit year", `"05/98"`, 1998, false},
+		{"2-digit string", `"98"`, 1998, false},
+		{"2-digit number", `98`, 1998, false},
+		{"this year in 2 digits", `"` + time.Now().Format("06") + `"`, now, false},
+		{"next year in 2 digits is a century ago", `"` + time.Now().AddDate(1, 0, 0).Format("06") + `"`, now + 1 - 100, false},
+		{"single digit", `5`, now - now%100 + 5, false},
+		{"zero", `0`, nil, false},
+		{"empty", `""`, nil, false},
+		{"null", `null`, nil, false},
+		{"fraction", `1998.5`, nil, true},
+		{"negative", `-5`, nil, true},
+		{"long number string", `"1234567"`, nil, true},
+		{"text", `"soon"`, nil, true},
+		{"bool", `true`, nil, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := value(t, app_mapping.Field{Path: "$", Transforms: []string{"year"}}, decode(t, tt.value))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldCompile(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		transforms []string
+	}{
+		{"empty path", " ", nil},
+		{"empty key", "$.vehicle..brand", nil},
+		{"unclosed index", "$.persons[0", nil},
+		{"bad index", "$.persons[first]", nil},
+		{"unclosed key", "$['reg info", nil},
+		{"unclosed filter", "$.persons[?(@.role == 'owner'", nil},
+		{"filter without operator", "$.persons[?(@.role)]", nil},
+		{"filter without @", "$.persons[?(role == 'owner')]", nil},
+		{"bad filter value", "$.persons[?(@.role == owner)]", nil},
+		{"unknown transform", "$.vehicle.brand", []string{"trim", "shout"}},
+		{"word without number", "$.vehicle.brand", []string{"word:last"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			field := app_mapping.Field{Path: tt.path, Transforms: tt.transforms}
+			if err := field.Compile(); err == nil {
+				t.Errorf("got no error for %+v", field)
+			}
+		})
+	}
+}
+
+func TestFieldValueNotCompiled(t *testing.T) {
+	field := app_mapping.Field{Path: "$.vehicle.brand"}
+	if _, err := field.Value(decode(t, documentJSON)); err == nil {
+		t.Error("got no error reading a field that is not compiled")
+	}
+}
+
+func TestMappingCompile(t *testing.T) {
+	var mapping app_mapping.Mapping
+	data := `{"mark": "$.vehicle.brand", "model": {"path": "$.vehicle.model", "transforms": ["trim", "upper"]}}`
+	if err := json.Unmarshal([]byte(data), &mapping); err != nil {
+		t.Fatalf("failed to unmarshal mapping: %v", err)
+	}
+	if err := mapping.Compile(); err != nil {
+		t.Fatalf("failed to compile mapping: %v", err)
+	}
+
+	document := decode(t, documentJSON)
+	for target, want := range map[string]any{"mark": "lada", "model": "VESTA"} {
+		got, err := mapping[target].Value(document)
+		if err != nil {
+			t.Fatalf("%s: got error %v", target, err)
+		}
+		if got != want {
+			t.Errorf("%s: got %#v, want %#v", target, got, want)
+		}
+	}
+
+	mapping["year"] = app_mapping.Field{Path: "$.vehicle.year", Transforms: []string{"yearly"}}
+	err := mapping.Compile()
+	if err == nil || !strings.HasPrefix(err.Error(), "year: ") {
+		t.Errorf("got error %v, want it reported for year", err)
+	}
+}
@@ -0,0 +1,292 @@
+package app_mapping
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	stepKey = iota
+	stepIndex
+	stepWildcard
+	stepFilter
+)
+
+// step is a part of a path: a key of an object, an index of an array
+// counting from the end when negative, every member of either, or the
+// elements of an array that pass a filter.
+type step struct {
+	kind   int
+	key    string
+	index  int
+	filter *filter
+}
+
+// filter compares the value at path, relative to an element, with value.
+type filter struct {
+	path  []step
+	equal bool
+	value any
+}
+
+// parsePath reads a JSONPath-like path:
+//
+//	$.vehicle.brand
+//	owner.firstName
+//	$.owners[0].name
+//	$.owners[-1].name
+//	$['reg info'].year
+//	$.persons[?(@.role == 'owner')].name
+//	$.documents[*].year
+//
+// The leading $ may be left out. Numeric keys index arrays as well, so the
+// dotted paths owners.0.name and owners[0].name are the same.
+func parsePath(path string) ([]step, error) {
+	rest := strings.TrimSpace(path)
+	if rest == "" {
+		return nil, invalid("empty path", path)
+	}
+	rest = strings.TrimPrefix(rest, "$")
+	if rest != "" && rest[0] != '.' && rest[0] != '[' {
+		rest = "." + rest
+	}
+
+	var steps []step
+	for rest != "" {
+		var next step
+		var err error
+		switch rest[0] {
+		case '.':
+			next, rest, err = parseDot(rest[1:], path)
+		case '[':
+			next, rest, err = parseBracket(rest[1:], path)
+		default:
+			err = invalid("unexpected character in path", path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, next)
+	}
+
+	return steps, nil
+}
+
+func parseDot(rest string, path string) (step, string, error) {
+	if strings.HasPrefix(rest, "*") {
+		return step{kind: stepWildcard}, rest[1:], nil
+	}
+	end := strings.IndexAny(rest, ".[")
+	if end < 0 {
+		end = len(rest)
+	}
+	if end == 0 {
+		return step{}, "", invalid("empty key in path", path)
+	}
+
+	return step{kind: stepKey, key: rest[:end]}, rest[end:], nil
+}
+
+func parseBracket(rest string, path string) (step, string, error) {
+	switch {
+	case strings.HasPrefix(rest, "*]"):
+		return step{kind: stepWildcard}, rest[2:], nil
+	case strings.HasPrefix(rest, "'"), strings.HasPrefix(rest, `"`):
+		end := strings.IndexByte(rest[1:], rest[0])
+		if end < 0 || !strings.HasPrefix(rest[end+2:], "]") {
+			return step{}, "", invalid("unclosed key in path", path)
+		}
+		return step{kind: stepKey, key: rest[1 : end+1]}, rest[end+3:], nil
+	case strings.HasPrefix(rest, "?("):
+		end := strings.Index(rest, ")]")
+		if end < 0 {
+			return step{}, "", invalid("unclosed filter in path", path)
+		}
+		filter, err := parseFilter(rest[2:end], path)
+		if err != nil {
+			return step{}, "", err
+		}
+		return step{kind: stepFilter, filter: filter}, rest[end+2:], nil
+	}
+
+	end := strings.IndexByte(rest, ']')
+	if end < 0 {
+		return step{}, "", invalid("unclosed index in path", path)
+	}
+	index, err := strconv.Atoi(strings.TrimSpace(rest[:end]))
+	if err != nil {
+		return step{}, "", invalid("bad index in path", path)
+	}
+
+	return step{kind: stepIndex, index: index}, rest[end+1:], nil
+}
+
+// parseFilter reads @.path == value or @.path != value, the value being a
+// quoted string, a number, true, false or null.
+func parseFilter(expression string, path string) (*filter, error) {
+	operator, equal := "==", true
+	at := strings.Index(expression, operator)
+	if at < 0 {
+		operator, equal = "!=", false
+		at = strings.Index(expression, operator)
+	}
+	if at < 0 {
+		return nil, invalid("filter must compare with == or !=", path)
+	}
+
+	left := strings.TrimSpace(expression[:at])
+	if !strings.HasPrefix(left, "@") {
+		return nil, invalid("filter must start with @", path)
+	}
+	var steps []step
+	if left != "@" {
+		var err error
+		if steps, err = parsePath("$" + left[1:]); err != nil {
+			return nil, err
+		}
+	}
+
+	right := strings.TrimSpace(expression[at+len(operator):])
+	var value any
+	if len(right) >= 2 && right[0] == '\'' && right[len(right)-1] == '\'' {
+		value = right[1 : len(right)-1]
+	} else {
+		decoder := json.NewDecoder(strings.NewReader(right))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil || decoder.More() {
+			return nil, invalid("bad filter value in path", path)
+		}
+	}
+
+	return &filter{path: steps, equal: equal, value: value}, nil
+}
+
+// evaluate returns every value the steps lead to from document.
+func evaluate(steps []step, document any) []any {
+	values := []any{document}
+	for _, s := range steps {
+		var next []any
+		for _, value := range values {
+			next = append(next, s.apply(value)...)
+		}
+		values = next
+	}
+
+	return values
+}
+
+func (s step) apply(value any) []any {
+	switch node := value.(type) {
+	case map[string]any:
+		switch s.kind {
+		case stepKey:
+			if member, ok := node[s.key]; ok {
+				return []any{member}
+			}
+		case stepWildcard:
+			keys := make([]string, 0, len(node))
+			for key := range node {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			members := make([]any, 0, len(keys))
+			for _, key := range keys {
+				members = append(members, node[key])
+			}
+			return members
+		}
+	case []any:
+		switch s.kind {
+		case stepKey:
+			if index, err := strconv.Atoi(s.key); err == nil {
+				return element(node, index)
+			}
+		case stepIndex:
+			return element(node, s.index)
+		case stepWildcard:
+			return node
+		case stepFilter:
+			var elements []any
+			for _, item := range node {
+				if s.filter.match(item) {
+					elements = append(elements, item)
+				}
+			}
+			return elements
+		}
+	}
+
+	return nil
+}
+
+func element(elements []any, index int) []any {
+	if index < 0 {
+		index += len(elements)
+	}
+	if index < 0 || index >= len(elements) {
+		return nil
+	}
+
+	return []any{elements[index]}
+}
+
+func (f *filter) match(item any) bool {
+	values := evaluate(f.path, item)
+	if len(values) == 0 {
+		return false
+	}
+	for _, value := range values {
+		if same(value, f.value) {
+			return f.equal
+		}
+	}
+
+	return !f.equal
+}
+
+// same reports whether the scalars a and b are equal. Numbers are equal by
+// value whether they are float64 or json.Number, integers exactly.
+func same(a, b any) bool {
+	x, ok := number(a)
+	if !ok {
+		return scalar(a) && a == b
+	}
+	y, ok := number(b)
+	if !ok {
+		return false
+	}
+	if i, err := x.Int64(); err == nil {
+		if j, err := y.Int64(); err == nil {
+			return i == j
+		}
+	}
+	f, err := x.Float64()
+	if err != nil {
+		return false
+	}
+	g, err := y.Float64()
+
+	return err == nil && f == g
+}
+
+func number(value any) (json.Number, bool) {
+	switch value := value.(type) {
+	case json.Number:
+		return value, true
+	case float64:
+		return json.Number(strconv.FormatFloat(value, 'f', -1, 64)), true
+	}
+
+	return "", false
+}
+
+func scalar(value any) bool {
+	switch value.(type) {
+	case nil, string, bool:
+		return true
+	}
+
+	return false
+}
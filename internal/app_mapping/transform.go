@@ -0,0 +1,233 @@
+package app_mapping
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// transform changes a selected value, nil when nothing was selected.
+type transform func(value any) (any, error)
+
+// parseTransform reads a transform given as its name, followed by its
+// argument after a colon when it takes one:
+//
+//	trim, lower, upper, title   change the case of a string
+//	word:N                      takes the Nth word, counting from the end when negative
+//	default:V                   gives V when nothing or an empty string was selected
+//	string, number              convert between strings and numbers
+//	year                        a year, from a number, a date or a 2-digit year
+//	age                         a year, from an age in years
+//	unix, unixMs                a year, from a Unix time in seconds or milliseconds
+func parseTransform(spec string) (transform, error) {
+	name, argument, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	switch name {
+	case "trim":
+		return onString(strings.TrimSpace), nil
+	case "lower":
+		return onString(strings.ToLower), nil
+	case "upper":
+		return onString(strings.ToUpper), nil
+	case "title":
+		return onString(title), nil
+	case "word":
+		n, err := strconv.Atoi(argument)
+		if err != nil {
+			return nil, invalid("word takes a number", spec)
+		}
+		return onString(func(value string) string {
+			words := strings.Fields(value)
+			i := n
+			if i < 0 {
+				i += len(words)
+			}
+			if i < 0 || i >= len(words) {
+				return ""
+			}
+			return words[i]
+		}), nil
+	case "default":
+		return func(value any) (any, error) {
+			if value == nil || value == "" {
+				return argument, nil
+			}
+			return value, nil
+		}, nil
+	case "string":
+		return func(value any) (any, error) {
+			if value == nil {
+				return nil, nil
+			}
+			return toString(value)
+		}, nil
+	case "number":
+		return onNumber(func(value float64) any { return value }), nil
+	case "year":
+		return toYear, nil
+	case "age":
+		return onNumber(func(value float64) any {
+			return time.Now().Year() - int(value)
+		}), nil
+	case "unix":
+		return onNumber(func(value float64) any {
+			return time.Unix(int64(value), 0).UTC().Year()
+		}), nil
+	case "unixMs":
+		return onNumber(func(value float64) any {
+			return time.UnixMilli(int64(value)).UTC().Year()
+		}), nil
+	}
+
+	return nil, invalid("unknown transform", spec)
+}
+
+func onString(change func(string) string) transform {
+	return func(value any) (any, error) {
+		if value == nil {
+			return nil, nil
+		}
+		text, err := toString(value)
+		if err != nil {
+			return nil, err
+		}
+		return change(text), nil
+	}
+}
+
+func onNumber(change func(float64) any) transform {
+	return func(value any) (any, error) {
+		if value == nil {
+			return nil, nil
+		}
+		number, err := toNumber(value)
+		if err != nil {
+			return nil, err
+		}
+		return change(number), nil
+	}
+}
+
+func toString(value any) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(value), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	}
+
+	return "", invalid("not a string", value)
+}
+
+func toNumber(value any) (float64, error) {
+	switch value := value.(type) {
+	case float64:
+		return value, nil
+	case json.Number:
+		number, err := value.Float64()
+		if err != nil {
+			return 0, invalid("not a number", value)
+		}
+		return number, nil
+	case int:
+		return float64(value), nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0, invalid("not a number", value)
+		}
+		return number, nil
+	}
+
+	return 0, invalid("not a number", value)
+}
+
+var fourDigits = regexp.MustCompile(`\b\d{4}\b`)
+
+// toYear reads a year from a number or from a string holding a year or a
+// date, a 2-digit year ending it when it has no 4-digit one. Years given
+// with two digits are taken as the latest such year that is not in the
+// future. Zero means no year.
+func toYear(value any) (any, error) {
+	var year int
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		if value != math.Trunc(value) {
+			return nil, invalid("not a year", value)
+		}
+		year = int(value)
+	case int:
+		year = value
+	case json.Number:
+		if number, err := value.Int64(); err == nil {
+			year = int(number)
+			break
+		}
+		number, err := value.Float64()
+		if err != nil {
+			return nil, invalid("not a year", value)
+		}
+		return toYear(number)
+	case string:
+		text := strings.TrimSpace(value)
+		if text == "" {
+			return nil, nil
+		}
+		if match := fourDigits.FindString(text); match != "" {
+			year, _ = strconv.Atoi(match)
+			break
+		}
+		parts := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsDigit(r) })
+		if len(parts) == 0 || len(parts[len(parts)-1]) > 2 {
+			return nil, invalid("not a year", value)
+		}
+		number, _ := strconv.Atoi(parts[len(parts)-1])
+		return twoDigitYear(number), nil
+	default:
+		return nil, invalid("not a year", value)
+	}
+
+	if year == 0 {
+		return nil, nil
+	}
+	if year < 0 {
+		return nil, invalid("not a year", value)
+	}
+	if year > 0 && year < 100 {
+		year = twoDigitYear(year)
+	}
+
+	return year, nil
+}
+
+func twoDigitYear(year int) int {
+	now := time.Now().Year()
+	year += now - now%100
+	if year > now {
+		year -= 100
+	}
+
+	return year
+}
+
+func title(value string) string {
+	words := strings.Fields(value)
+	for i, word := range words {
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,24 @@
+package app_time
+
+import (
+	"fmt"
+	"time"
+
+	"effective_mobile_2/internal/app_error"
+)
+
+const dateLayout = "2006-01-02"
+
+// ParseDate reads either a date, meaning its start in UTC, or an RFC 3339
+// timestamp.
+func ParseDate(value string) (time.Time, error) {
+	if date, err := time.Parse(dateLayout, value); err == nil {
+		return date, nil
+	}
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s - %s", app_error.ErrInvalidArgument, "unknown date format", value)
+	}
+
+	return date, nil
+}
@@ -1,6 +1,13 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"effective_mobile_2/internal/app_mapping"
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
 )
@@ -10,10 +17,12 @@ type Config struct {
 	Postgres Postgres
 	Logger   Logger
 	Api      Api
+	Purge    Purge
 }
 
 type Http struct {
-	Address string `env:"HTTP_ADDRESS"`
+	Address         string `env:"HTTP_ADDRESS"`
+	IfMatchRequired bool   `env:"HTTP_IF_MATCH_REQUIRED" env-default:"true"`
 }
 
 type Postgres struct {
@@ -25,7 +34,86 @@ type Logger struct {
 }
 
 type Api struct {
-	CarInfo string `env:"API_CAR_INFO"`
+	CarInfo            string        `env:"API_CAR_INFO"`
+	CarInfoConcurrency int           `env:"API_CAR_INFO_CONCURRENCY" env-default:"4"`
+	CarInfoTimeout     time.Duration `env:"API_CAR_INFO_TIMEOUT" env-default:"5s"`
+	CarInfoRetries     int           `env:"API_CAR_INFO_RETRIES" env-default:"3"`
+	CarInfoBackoff     time.Duration `env:"API_CAR_INFO_BACKOFF" env-default:"200ms"`
+	CarInfoMaxBackoff  time.Duration `env:"API_CAR_INFO_MAX_BACKOFF" env-default:"5s"`
+
+	CarInfoBreakerFailures    int           `env:"API_CAR_INFO_BREAKER_FAILURES" env-default:"5"`
+	CarInfoBreakerOpenTimeout time.Duration `env:"API_CAR_INFO_BREAKER_OPEN_TIMEOUT" env-default:"30s"`
+	CarInfoBreakerProbes      int           `env:"API_CAR_INFO_BREAKER_PROBES" env-default:"1"`
+
+	// CarInfoCacheStore keeps the car info cache in "memory" or "postgres",
+	// CarInfoCacheSize only bounds the one in memory.
+	CarInfoCacheStore       string        `env:"API_CAR_INFO_CACHE_STORE" env-default:"memory"`
+	CarInfoCacheSize        int           `env:"API_CAR_INFO_CACHE_SIZE" env-default:"10000"`
+	CarInfoCacheTTL         time.Duration `env:"API_CAR_INFO_CACHE_TTL" env-default:"24h"`
+	CarInfoCacheNotFoundTTL time.Duration `env:"API_CAR_INFO_CACHE_NOT_FOUND_TTL" env-default:"1h"`
+
+	CarInfoProviders string `env:"API_CAR_INFO_PROVIDERS"`
+	CarInfoMerge     bool   `env:"API_CAR_INFO_MERGE" env-default:"false"`
+	// Providers are read from the CarInfoProviders file, without it CarInfo
+	// is the only provider.
+	Providers []CarInfoProvider
+}
+
+// CarInfoProvider is a car info API asked in order of Priority, the lowest
+// first. Fields lists the car info fields taken from it when merging, all
+// of them when empty. Mapping tells where in the response the car info
+// fields are and how to normalize them, see app_mapping.
+type CarInfoProvider struct {
+	Name     string              `json:"name"`
+	Url      string              `json:"url"`
+	Path     string              `json:"path"`
+	Priority int                 `json:"priority"`
+	Auth     CarInfoProviderAuth `json:"auth"`
+	Fields   []string            `json:"fields"`
+	Mapping  app_mapping.Mapping `json:"mapping"`
+}
+
+// CarInfoProviderAuth is a bearer Token, a basic Username and Password or
+// a Token sent in Header, depending on Type. Environment variables in the
+// values are expanded so secrets can stay out of the file.
+type CarInfoProviderAuth struct {
+	Type     string `json:"type"`
+	Header   string `json:"header"`
+	Token    string `json:"token"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// redacted stands for a secret in the printed auth.
+const redacted = "[REDACTED]"
+
+// String prints the auth with its secrets redacted.
+func (a CarInfoProviderAuth) String() string {
+	return fmt.Sprintf("{Type:%s Header:%s Token:%s Username:%s Password:%s}",
+		a.Type, a.Header, redact(a.Token), redact(a.Username), redact(a.Password))
+}
+
+// LogValue logs the auth with its secrets redacted.
+func (a CarInfoProviderAuth) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("type", a.Type),
+		slog.String("header", a.Header),
+		slog.String("token", redact(a.Token)),
+		slog.String("username", redact(a.Username)),
+		slog.String("password", redact(a.Password)),
+	)
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+type Purge struct {
+	CarRetention time.Duration `env:"PURGE_CAR_RETENTION" env-default:"720h"`
 }
 
 var cfg Config
@@ -40,6 +128,35 @@ func Parse() error {
 		return err
 	}
 
+	return parseProviders()
+}
+
+func parseProviders() error {
+	if cfg.Api.CarInfoProviders == "" {
+		cfg.Api.Providers = []CarInfoProvider{{Name: "default", Url: cfg.Api.CarInfo}}
+		return nil
+	}
+
+	data, err := os.ReadFile(cfg.Api.CarInfoProviders)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, &cfg.Api.Providers); err != nil {
+		return fmt.Errorf("%s: %w", cfg.Api.CarInfoProviders, err)
+	}
+	if len(cfg.Api.Providers) == 0 {
+		return fmt.Errorf("%s: no car info providers", cfg.Api.CarInfoProviders)
+	}
+	for i := range cfg.Api.Providers {
+		if err = cfg.Api.Providers[i].Mapping.Compile(); err != nil {
+			return fmt.Errorf("%s: %s: %w", cfg.Api.CarInfoProviders, cfg.Api.Providers[i].Name, err)
+		}
+		auth := &cfg.Api.Providers[i].Auth
+		auth.Token = os.ExpandEnv(auth.Token)
+		auth.Username = os.ExpandEnv(auth.Username)
+		auth.Password = os.ExpandEnv(auth.Password)
+	}
+
 	return nil
 }
 
@@ -2,7 +2,10 @@ package database
 
 import (
 	"effective_mobile_2/internal/config"
+	"effective_mobile_2/internal/repository/gorm/audit_log"
 	"effective_mobile_2/internal/repository/gorm/car"
+	"effective_mobile_2/internal/repository/gorm/car_info_cache"
+	"effective_mobile_2/internal/repository/gorm/car_ownership"
 	"effective_mobile_2/internal/repository/gorm/people"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -29,16 +32,90 @@ func Connect() error {
 	return nil
 }
 
+// searchStatements prepare case-insensitive and accent-tolerant search:
+// f_unaccent is an immutable wrapper around unaccent so it can be used in
+// index expressions, the trigram indexes back the LIKE filters and the
+// free-text search of the car repository, and idx_peoples_full_name_squished
+// backs owner deduplication.
+var searchStatements = []string{
+	"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+	"CREATE EXTENSION IF NOT EXISTS unaccent",
+	`CREATE OR REPLACE FUNCTION f_unaccent(text) RETURNS text AS
+		$$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$
+		LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT`,
+	"CREATE INDEX IF NOT EXISTS idx_cars_reg_num_trgm ON cars USING gin (f_unaccent(lower(reg_num)) gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_cars_mark_trgm ON cars USING gin (f_unaccent(lower(mark)) gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_cars_model_trgm ON cars USING gin (f_unaccent(lower(model)) gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_cars_search_trgm ON cars USING gin (f_unaccent(lower(reg_num || ' ' || mark || ' ' || model)) gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_peoples_name_trgm ON peoples USING gin (f_unaccent(lower(name)) gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_peoples_surname_trgm ON peoples USING gin (f_unaccent(lower(surname)) gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_peoples_patronymic_trgm ON peoples USING gin (f_unaccent(lower(patronymic)) gin_trgm_ops)",
+	"DROP INDEX IF EXISTS idx_peoples_full_name",
+	`CREATE INDEX IF NOT EXISTS idx_peoples_full_name_squished ON peoples (
+		f_unaccent(lower(regexp_replace(btrim(surname), '\s+', ' ', 'g'))),
+		f_unaccent(lower(regexp_replace(btrim(name), '\s+', ' ', 'g'))),
+		f_unaccent(lower(regexp_replace(btrim(COALESCE(patronymic, '')), '\s+', ' ', 'g'))))`,
+	"CREATE INDEX IF NOT EXISTS idx_peoples_search_trgm ON peoples USING gin (f_unaccent(lower(surname || ' ' || name || ' ' || COALESCE(patronymic, ''))) gin_trgm_ops)",
+}
+
+// backfillStatements give the rows stored before the timestamps were kept
+// the time of the migration, so they are not mistaken for missing ones by
+// the created and updated filters.
+var backfillStatements = []string{
+	"UPDATE cars SET created_at = now() WHERE created_at IS NULL",
+	"UPDATE cars SET updated_at = now() WHERE updated_at IS NULL",
+	"UPDATE peoples SET created_at = now() WHERE created_at IS NULL",
+	"UPDATE peoples SET updated_at = now() WHERE updated_at IS NULL",
+}
+
+// preMigrateStatements run before the auto migration: the plain unique
+// constraint on cars.reg_num is replaced by a unique index over the cars
+// that are not deleted.
+var preMigrateStatements = []string{
+	"ALTER TABLE IF EXISTS cars DROP CONSTRAINT IF EXISTS cars_reg_num_key",
+	"ALTER TABLE IF EXISTS cars DROP CONSTRAINT IF EXISTS uni_cars_reg_num",
+}
+
 func Migrate() error {
+	for _, statement := range preMigrateStatements {
+		if err := db.Gorm.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
 	err := db.Gorm.AutoMigrate(
 		&people.People{},
 		&car.Car{},
+		&car_ownership.CarOwnership{},
+		&audit_log.AuditLog{},
+		&car_info_cache.CarInfoCache{},
 	)
 
 	if err != nil {
 		return err
 	}
 
+	for _, statement := range searchStatements {
+		if err = db.Gorm.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, statement := range backfillStatements {
+		if err = db.Gorm.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	// cars stored before the ownership history was kept get an open period
+	// of unknown start for their current owner
+	err = db.Gorm.Exec(`INSERT INTO car_ownerships (car_id, owner_id)
+		SELECT cars.id, cars.owner_id FROM cars
+		WHERE NOT EXISTS (SELECT 1 FROM car_ownerships WHERE car_ownerships.car_id = cars.id)`).Error
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
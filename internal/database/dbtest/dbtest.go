@@ -0,0 +1,59 @@
+// Package dbtest runs repository tests against a real Postgres database
+// given by TEST_POSTGRES_URL. Without it the tests are skipped.
+package dbtest
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/config"
+	"effective_mobile_2/internal/database"
+	"gorm.io/gorm"
+)
+
+var (
+	once    sync.Once
+	openErr error
+)
+
+var errRollback = errors.New("rollback")
+
+// Open connects to and migrates the test database once per test binary.
+func Open(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	url := os.Getenv("TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("TEST_POSTGRES_URL is not set")
+	}
+	once.Do(func() {
+		app_log.Setup("error")
+		config.Cfg().Postgres.Url = url
+		if openErr = database.Connect(); openErr == nil {
+			openErr = database.Migrate()
+		}
+	})
+	if openErr != nil {
+		t.Fatalf("failed to open test database: %v", openErr)
+	}
+
+	return database.Db().Gorm
+}
+
+// Rollback runs fn in a transaction that is always rolled back, so tests
+// leave the database as they found it. Repositories built on the tx given
+// to fn take part in the transaction.
+func Rollback(t *testing.T, db *gorm.DB, fn func(tx *gorm.DB)) {
+	t.Helper()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		fn(tx)
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("failed to roll back test transaction: %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+package command
+
+type AuditIndex struct {
+	EntityType *string
+	EntityID   *uint
+	Action     *string
+	Actor      *string
+	RequestID  *string
+	From       *string
+	To         *string
+	Page       *int
+	Count      *int
+}
@@ -1,29 +1,85 @@
 package command
 
+import "time"
+
 type CarIndex struct {
-	RegNum       *string
-	Mark         *string
-	Model        *string
-	Year         *int
-	OwnerName    *string
-	OwnerSurname *string
-	Order        *string
-	Page         *int
-	Count        *int
+	RegNum          *string
+	RegNumPrefix    *string
+	Marks           []string
+	MarkMatch       *string
+	Model           *string
+	Year            *int
+	YearFrom        *int
+	YearTo          *int
+	OwnerID         *uint
+	OwnedAt         *string
+	OwnerName       *string
+	OwnerSurname    *string
+	OwnerPatronymic *string
+	OwnerMatch      *string
+	HasPatronymic   *bool
+	Search          *string
+	CreatedFrom     *string
+	CreatedTo       *string
+	UpdatedSince    *string
+	IncludeDeleted  *bool
+	OnlyDeleted     *bool
+	Order           *string
+	Sort            *string
+	Page            *int
+	Count           *int
+	Cursor          *string
 }
 
+type CarShow struct {
+	ID int
+}
+
+const (
+	CarStoreOnConflictError   = "error"
+	CarStoreOnConflictSkip    = "skip"
+	CarStoreOnConflictRefresh = "refresh"
+)
+
 type CarStore struct {
-	RegNums []string
+	RegNums    []string
+	OnConflict string
 }
 
 type CarUpdate struct {
-	ID     int
-	RegNum *string
-	Mark   *string
-	Model  *string
-	Year   *int
+	ID      int
+	RegNum  *string
+	Mark    *string
+	Model   *string
+	Year    *int
+	IfMatch *string
 }
 
 type CarDelete struct {
 	ID int
 }
+
+type CarRestore struct {
+	ID int
+}
+
+type CarHistory struct {
+	ID    int
+	Page  *int
+	Count *int
+}
+
+type CarTransfer struct {
+	ID      int
+	OwnerID uint
+	Date    *time.Time
+}
+
+type CarOwners struct {
+	ID int
+}
+
+type CarOwner struct {
+	ID int
+	At *string
+}
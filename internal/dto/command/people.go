@@ -0,0 +1,42 @@
+package command
+
+type PeopleIndex struct {
+	Name       *string
+	Surname    *string
+	Patronymic *string
+	Order      *string
+	Page       *int
+	Count      *int
+}
+
+type PeopleShow struct {
+	ID int
+}
+
+type PeopleStore struct {
+	Name       string
+	Surname    string
+	Patronymic *string
+}
+
+type PeopleUpdate struct {
+	ID         int
+	Name       *string
+	Surname    *string
+	Patronymic *string
+}
+
+type PeopleDelete struct {
+	ID int
+}
+
+type PeopleMerge struct {
+	ID           int
+	DuplicateIDs []int
+	DryRun       bool
+}
+
+type PeopleDuplicates struct {
+	Page  *int
+	Count *int
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+const (
+	AuditEntityCar    = "car"
+	AuditEntityPeople = "people"
+)
+
+const (
+	AuditActionCreate   = "create"
+	AuditActionUpdate   = "update"
+	AuditActionDelete   = "delete"
+	AuditActionRestore  = "restore"
+	AuditActionTransfer = "transfer"
+	AuditActionMerge    = "merge"
+	AuditActionPurge    = "purge"
+)
+
+type AuditLog struct {
+	ID         uint           `json:"id"`
+	EntityType string         `json:"entityType"`
+	EntityID   uint           `json:"entityID"`
+	Action     string         `json:"action"`
+	Before     map[string]any `json:"before,omitempty"`
+	After      map[string]any `json:"after,omitempty"`
+	RequestID  string         `json:"requestID"`
+	Actor      string         `json:"actor"`
+	CreatedAt  time.Time      `json:"createdAt"`
+}
+
+type AuditLogList struct {
+	Items      []AuditLog `json:"items"`
+	Pagination Pagination `json:"pagination"`
+}
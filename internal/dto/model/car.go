@@ -1,8 +1,35 @@
 package model
 
+import "time"
+
 type Car struct {
-	ID      uint   `json:"id"`
-	RegNum  string `json:"regNum"`
-	OwnerID uint   `json:"ownerID"`
+	ID        uint       `json:"id"`
+	RegNum    string     `json:"regNum"`
+	OwnerID   uint       `json:"ownerID"`
+	Version   uint       `json:"version"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
 	CarInfo
 }
+
+type CarList struct {
+	Items      []Car       `json:"items"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Cursor     *Cursor     `json:"cursor,omitempty"`
+}
+
+const (
+	CarStoreStatusCreated   = "created"
+	CarStoreStatusSkipped   = "skipped"
+	CarStoreStatusRefreshed = "refreshed"
+	CarStoreStatusFailed    = "failed"
+)
+
+type CarStoreResult struct {
+	RegNum  string  `json:"regNum"`
+	Status  string  `json:"status"`
+	Car     *Car    `json:"car,omitempty"`
+	Error   *string `json:"error,omitempty"`
+	Message *string `json:"message,omitempty"`
+}
@@ -1,8 +1,28 @@
 package model
 
+import "time"
+
+const (
+	CarInfoFieldMark  = "mark"
+	CarInfoFieldModel = "model"
+	CarInfoFieldYear  = "year"
+	CarInfoFieldOwner = "owner"
+)
+
 type CarInfo struct {
 	Mark  string  `json:"mark"`
 	Model string  `json:"model"`
 	Year  *int    `json:"year"`
 	Owner *People `json:"owner,omitempty"`
+	// Sources names the provider that supplied each field.
+	Sources map[string]string `json:"sources,omitempty"`
+}
+
+// CarInfoCache is a cached car info lookup of RegNum: its CarInfo, or that
+// the car is not known when NotFound.
+type CarInfoCache struct {
+	RegNum    string
+	CarInfo   *CarInfo
+	NotFound  bool
+	ExpiresAt time.Time
 }
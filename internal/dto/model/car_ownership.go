@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type CarOwnership struct {
+	ID        uint       `json:"id"`
+	CarID     uint       `json:"carID"`
+	OwnerID   uint       `json:"ownerID"`
+	Owner     *People    `json:"owner,omitempty"`
+	OwnedFrom *time.Time `json:"ownedFrom"`
+	OwnedTo   *time.Time `json:"ownedTo"`
+}
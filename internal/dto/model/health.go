@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+const (
+	HealthStatusOk       = "ok"
+	HealthStatusDegraded = "degraded"
+)
+
+const (
+	CircuitBreakerClosed   = "closed"
+	CircuitBreakerOpen     = "open"
+	CircuitBreakerHalfOpen = "half-open"
+)
+
+type CircuitBreaker struct {
+	State    string     `json:"state"`
+	Failures int        `json:"failures"`
+	OpenedAt *time.Time `json:"openedAt,omitempty"`
+}
+
+type Health struct {
+	Status string `json:"status"`
+	// CarInfo holds the circuit breaker of every car info provider by name.
+	CarInfo map[string]CircuitBreaker `json:"carInfo"`
+}
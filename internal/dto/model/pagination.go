@@ -0,0 +1,22 @@
+package model
+
+type Pagination struct {
+	Page       int   `json:"page"`
+	Count      int   `json:"count"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"totalPages"`
+}
+
+type Cursor struct {
+	Count      int     `json:"count"`
+	NextCursor *string `json:"nextCursor"`
+}
+
+func NewPagination(page, count int, total int64) Pagination {
+	pagination := Pagination{Page: page, Count: count, Total: total}
+	if count > 0 {
+		pagination.TotalPages = int((total + int64(count) - 1) / int64(count))
+	}
+
+	return pagination
+}
@@ -1,8 +1,25 @@
 package model
 
+import "time"
+
 type People struct {
-	ID         uint    `json:"id"`
-	Name       string  `json:"name"`
-	Surname    string  `json:"surname"`
-	Patronymic *string `json:"patronymic"`
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Surname    string     `json:"surname"`
+	Patronymic *string    `json:"patronymic"`
+	ExternalID *string    `json:"externalID,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+}
+
+type PeopleMerge struct {
+	Target         People `json:"target"`
+	MergedIDs      []int  `json:"mergedIDs"`
+	CarsReassigned int64  `json:"carsReassigned"`
+	DryRun         bool   `json:"dryRun"`
+}
+
+type PeopleDuplicate struct {
+	FullName string   `json:"fullName"`
+	Peoples  []People `json:"peoples"`
 }
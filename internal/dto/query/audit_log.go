@@ -0,0 +1,27 @@
+package query
+
+import "time"
+
+// AuditLogCreate records a change of an entity. Before and After are the
+// entity as it was and as it became, nil when it did not exist.
+type AuditLogCreate struct {
+	EntityType string
+	EntityID   uint
+	Action     string
+	Before     any
+	After      any
+	RequestID  string
+	Actor      string
+}
+
+type AuditLogList struct {
+	EntityType *string
+	EntityID   *uint
+	Action     *string
+	Actor      *string
+	RequestID  *string
+	From       *time.Time
+	To         *time.Time
+	Page       int
+	Count      int
+}
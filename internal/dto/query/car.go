@@ -1,15 +1,74 @@
 package query
 
+import "time"
+
 type CarList struct {
-	RegNum       *string
-	Mark         *string
-	Model        *string
-	Year         *int
-	OwnerName    *string
-	OwnerSurname *string
-	Order        string
-	Page         int
-	Count        int
+	RegNum          *string
+	RegNumPrefix    *string
+	Marks           []string
+	MarkMatch       string
+	Model           *string
+	Year            *int
+	YearFrom        *int
+	YearTo          *int
+	OwnerID         *uint
+	OwnedAt         *time.Time
+	OwnerName       *string
+	OwnerSurname    *string
+	OwnerPatronymic *string
+	OwnerMatch      string
+	HasPatronymic   *bool
+	Search          *string
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+	UpdatedSince    *time.Time
+	IncludeDeleted  bool
+	OnlyDeleted     bool
+	Sort            []CarSort
+	Page            int
+	Count           int
+	Cursor          *CarCursor
+}
+
+const (
+	MatchExact    = "exact"
+	MatchPrefix   = "prefix"
+	MatchContains = "contains"
+)
+
+const (
+	CarSortID              = "id"
+	CarSortRegNum          = "regNum"
+	CarSortMark            = "mark"
+	CarSortModel           = "model"
+	CarSortYear            = "year"
+	CarSortOwnerName       = "owner.name"
+	CarSortOwnerSurname    = "owner.surname"
+	CarSortOwnerPatronymic = "owner.patronymic"
+	CarSortRelevance       = "relevance"
+)
+
+type CarSort struct {
+	Field string
+	Desc  bool
+}
+
+// CarCursor holds the sort key values of the last seen car,
+// one per CarList.Sort entry.
+type CarCursor struct {
+	Values []string
+}
+
+type CarGet struct {
+	ID int
+}
+
+type CarListByRegNums struct {
+	RegNums []string
+}
+
+type CarListByOwnerIDs struct {
+	OwnerIDs []uint
 }
 
 type CarCreate struct {
@@ -17,17 +76,34 @@ type CarCreate struct {
 	Mark    string
 	Model   string
 	Year    *int
+	Sources map[string]string
 	OwnerID uint
 }
 
 type CarUpdate struct {
-	ID     int
-	RegNum *string
-	Mark   *string
-	Model  *string
-	Year   *int
+	ID      int
+	RegNum  *string
+	Mark    *string
+	Model   *string
+	Year    *int
+	Sources map[string]string
+	OwnerID *uint
+	Version *uint
 }
 
 type CarDelete struct {
 	ID int
 }
+
+type CarRestore struct {
+	ID int
+}
+
+type CarPurge struct {
+	DeletedBefore time.Time
+}
+
+type CarReassignOwner struct {
+	FromOwnerIDs []uint
+	ToOwnerID    uint
+}
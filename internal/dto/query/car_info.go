@@ -1,5 +1,26 @@
 package query
 
+import (
+	"time"
+
+	"effective_mobile_2/internal/dto/model"
+)
+
 type CarInfo struct {
 	RegNum string
+	// Fresh skips the cached car info, the new one is cached still.
+	Fresh bool
+}
+
+type CarInfoCacheGet struct {
+	RegNum string
+}
+
+// CarInfoCacheSet caches the CarInfo of RegNum, or that the car info
+// providers do not know it when NotFound, until ExpiresAt.
+type CarInfoCacheSet struct {
+	RegNum    string
+	CarInfo   *model.CarInfo
+	NotFound  bool
+	ExpiresAt time.Time
 }
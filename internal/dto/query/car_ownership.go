@@ -0,0 +1,28 @@
+package query
+
+import "time"
+
+type CarOwnershipList struct {
+	CarID uint
+}
+
+type CarOwnershipAt struct {
+	CarID uint
+	At    time.Time
+}
+
+type CarOwnershipOpen struct {
+	CarID     uint
+	OwnerID   uint
+	OwnedFrom time.Time
+}
+
+type CarOwnershipClose struct {
+	CarID   uint
+	OwnedTo time.Time
+}
+
+type CarOwnershipReassignOwner struct {
+	FromOwnerIDs []uint
+	ToOwnerID    uint
+}
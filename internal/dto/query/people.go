@@ -1,7 +1,37 @@
 package query
 
+type PeopleList struct {
+	Name       *string
+	Surname    *string
+	Patronymic *string
+	Order      string
+	Page       int
+	Count      int
+}
+
+type PeopleGet struct {
+	ID int
+}
+
 type PeopleCreate struct {
 	Name       string
 	Surname    string
 	Patronymic *string
+	ExternalID *string
+}
+
+type PeopleUpdate struct {
+	ID         int
+	Name       *string
+	Surname    *string
+	Patronymic *string
+}
+
+type PeopleDelete struct {
+	ID int
+}
+
+type PeopleDuplicates struct {
+	Page  int
+	Count int
 }
@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"log/slog"
+	"net/http"
+
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/dto/command"
+	"effective_mobile_2/internal/handler/http/dto/request"
+	"effective_mobile_2/internal/handler/http/dto/response"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/gorilla/schema"
+)
+
+type Handler struct {
+	service service
+}
+
+func New(service service) *Handler {
+	return &Handler{service: service}
+}
+
+// Index lists the recorded changes based on query parameters
+// @Summary List audit logs
+// @Description Get the recorded changes of cars and peoples, the latest first.
+// @Description Before and after hold only the fields the change touched.
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param entityType query string false "Entity type filter (car or people)"
+// @Param entityID query int false "Entity ID filter"
+// @Param action query string false "Action filter (create, update, delete, restore, transfer or merge)"
+// @Param actor query string false "Actor filter, as asserted by the client in the X-Actor header of the change, not authenticated"
+// @Param requestID query string false "Request ID filter"
+// @Param from query string false "Date (2006-01-02) or RFC 3339 time the changes are made at or after"
+// @Param to query string false "Date (2006-01-02) or RFC 3339 time the changes are made before"
+// @Param page query int false "Page number for pagination"
+// @Param count query int false "Number of items per page"
+// @Success 200 {object} response.Page{items=[]model.AuditLog}
+// @Header 200 {integer} X-Total-Count "Total number of audit logs matching the filters"
+// @Header 200 {string} Link "Links to the first, prev, next and last pages"
+// @Failure 400 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api/audit [get]
+func (h *Handler) Index() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.audit.Index"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("searching audit logs")
+
+		var req request.AuditIndex
+		if err := schema.NewDecoder().Decode(&req, r.URL.Query()); err != nil {
+			log.Error("failed to decode", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		cmd := command.AuditIndex{
+			EntityType: req.EntityType,
+			EntityID:   req.EntityID,
+			Action:     req.Action,
+			Actor:      req.Actor,
+			RequestID:  req.RequestID,
+			From:       req.From,
+			To:         req.To,
+			Page:       req.Page,
+			Count:      req.Count,
+		}
+		auditLogList, err := h.service.Index(r.Context(), &cmd)
+		if err != nil {
+			log.Error("failed to search audit logs", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("searched audit logs", slog.Any("auditLogList", auditLogList))
+
+		response.Paginated(&w, r, auditLogList.Items, auditLogList.Pagination)
+	}
+}
@@ -0,0 +1,12 @@
+package audit
+
+import (
+	"context"
+
+	"effective_mobile_2/internal/dto/command"
+	"effective_mobile_2/internal/dto/model"
+)
+
+type service interface {
+	Index(ctx context.Context, cmd *command.AuditIndex) (*model.AuditLogList, error)
+}
@@ -26,20 +26,41 @@ func New(service service) *Handler {
 
 // Index lists all cars based on query parameters
 // @Summary List all cars
-// @Description Get a list of cars filtered by various parameters
+// @Description Get a list of cars filtered by various parameters.
+// @Description Passing the cursor parameter (empty for the first page) switches to keyset pagination:
+// @Description the response then carries nextCursor instead of page and total.
 // @Tags cars
 // @Accept json
 // @Produce json
 // @Param regNum query string false "Registration Number filter"
-// @Param mark query string false "Car mark filter"
+// @Param regNumPrefix query string false "Registration Number prefix filter"
+// @Param mark query []string false "Car mark filter, a car matches when its mark matches any of the values" collectionFormat(multi)
+// @Param markMatch query string false "How marks are matched (exact, prefix or contains), default contains for a single mark and exact for several"
 // @Param model query string false "Car model filter"
 // @Param year query int false "Car year filter"
+// @Param yearFrom query int false "Car year lower bound, inclusive"
+// @Param yearTo query int false "Car year upper bound, inclusive"
+// @Param ownerID query int false "Owner ID filter"
+// @Param ownedAt query string false "Date (2006-01-02) or RFC 3339 time the car was owned at, by ownerID when set"
 // @Param ownerName query string false "Owner name filter"
 // @Param ownerSurname query string false "Owner surname filter"
-// @Param order query string false "Order of results (asc or desc)"
+// @Param ownerPatronymic query string false "Owner patronymic filter"
+// @Param ownerMatch query string false "How owner name, surname and patronymic are matched (exact, prefix or contains, default contains)"
+// @Param hasPatronymic query bool false "Whether the owner has a patronymic"
+// @Param q query string false "Free-text search over reg number, mark, model and owner full name, ranked by relevance"
+// @Param createdFrom query string false "Date (2006-01-02) or RFC 3339 time the cars are created at or after"
+// @Param createdTo query string false "Date (2006-01-02) or RFC 3339 time the cars are created before"
+// @Param updatedSince query string false "Date (2006-01-02) or RFC 3339 time the cars are updated or deleted at or after"
+// @Param includeDeleted query bool false "Whether deleted cars are listed along the others"
+// @Param onlyDeleted query bool false "Whether only deleted cars are listed"
+// @Param order query string false "Order of results by id (asc or desc), also used as the sort tie-breaker"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (id, regNum, mark, model, year, owner.name, owner.surname, owner.patronymic, relevance)"
 // @Param page query int false "Page number for pagination"
 // @Param count query int false "Number of items per page"
-// @Success 200 {array} model.Car
+// @Param cursor query string false "Opaque keyset cursor returned as nextCursor"
+// @Success 200 {object} response.Page{items=[]model.Car,nextCursor=string} "A response.Page of cars, or a response.CursorPage of cars (items, count and nextCursor only) when cursor is passed"
+// @Header 200 {integer} X-Total-Count "Total number of cars matching the filters, not sent when cursor is passed"
+// @Header 200 {string} Link "Links to the first, prev, next and last pages, only to the next one when cursor is passed"
 // @Failure 400 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /api/cars [get]
@@ -61,40 +82,117 @@ func (h *Handler) Index() http.HandlerFunc {
 		}
 
 		cmd := command.CarIndex{
-			RegNum:       req.RegNum,
-			Mark:         req.Mark,
-			Model:        req.Model,
-			Year:         req.Year,
-			OwnerName:    req.OwnerName,
-			OwnerSurname: req.OwnerSurname,
-			Order:        req.Order,
-			Page:         req.Page,
-			Count:        req.Count,
-		}
-		cars, err := h.service.Index(&cmd)
+			RegNum:          req.RegNum,
+			RegNumPrefix:    req.RegNumPrefix,
+			Marks:           req.Mark,
+			MarkMatch:       req.MarkMatch,
+			Model:           req.Model,
+			Year:            req.Year,
+			YearFrom:        req.YearFrom,
+			YearTo:          req.YearTo,
+			OwnerID:         req.OwnerID,
+			OwnedAt:         req.OwnedAt,
+			OwnerName:       req.OwnerName,
+			OwnerSurname:    req.OwnerSurname,
+			OwnerPatronymic: req.OwnerPatronymic,
+			OwnerMatch:      req.OwnerMatch,
+			HasPatronymic:   req.HasPatronymic,
+			Search:          req.Q,
+			CreatedFrom:     req.CreatedFrom,
+			CreatedTo:       req.CreatedTo,
+			UpdatedSince:    req.UpdatedSince,
+			IncludeDeleted:  req.IncludeDeleted,
+			OnlyDeleted:     req.OnlyDeleted,
+			Order:           req.Order,
+			Sort:            req.Sort,
+			Page:            req.Page,
+			Count:           req.Count,
+			Cursor:          req.Cursor,
+		}
+		carList, err := h.service.Index(r.Context(), &cmd)
 		if err != nil {
 			log.Error("failed to search cars", slog.String("error", err.Error()))
 			response.Bad(&w, r, err)
 			return
 		}
 
-		log.Debug("searched cars", slog.Any("cars", cars))
+		log.Debug("searched cars", slog.Any("carList", carList))
 
-		response.Ok(&w, r, cars)
+		if carList.Cursor != nil {
+			response.Scrolled(&w, r, carList.Items, *carList.Cursor)
+			return
+		}
+		response.Paginated(&w, r, carList.Items, *carList.Pagination)
+	}
+}
+
+// Show returns a single car with its owner
+// @Summary Get car details
+// @Description Get a car by its ID with the owner embedded
+// @Tags cars
+// @Accept json
+// @Produce json
+// @Param id path int true "Car ID"
+// @Success 200 {object} model.Car
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Header 200 {string} ETag "Version of the car, to pass as If-Match on update"
+// @Router /api/cars/{id} [get]
+func (h *Handler) Show() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.car.Show"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("searching car")
+
+		idParam := chi.URLParam(r, "id")
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			log.Error("failed to convert", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		cmd := command.CarShow{ID: id}
+		car, err := h.service.Show(r.Context(), &cmd)
+		if err != nil {
+			log.Error("failed to search car", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("searched car", slog.Any("car", car))
+
+		response.ETag(&w, car.Version)
+		response.Ok(&w, r, car)
 	}
 }
 
 // Store creates new cars based on the provided data
 // @Summary Create new cars
-// @Description Add one or more new cars to the database
+// @Description Add one or more new cars to the database.
+// @Description In atomic mode (default) either all cars are created or none.
+// @Description In partial mode every regNum is stored independently and a per item result is returned.
+// @Description onConflict decides what happens to an already stored regNum: error (default) fails with 409,
+// @Description skip returns the stored car and refresh updates it from a new car info lookup.
+// @Description A regNum repeated in the batch is stored once and reported with the same car at each position.
 // @Tags cars
 // @Accept json
 // @Produce json
 // @Param request body request.CarStore true "New car details"
 // @Success 200 {array} model.Car
+// @Success 207 {array} model.CarStoreResult
 // @Failure 400 {object} response.Error
 // @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 412 {object} response.Error
 // @Failure 500 {object} response.Error
+// @Failure 503 {object} response.Error
+// @Header 503 {integer} Retry-After "Seconds until the car info provider is tried again"
 // @Router /api/cars [post]
 func (h *Handler) Store() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -118,8 +216,27 @@ func (h *Handler) Store() http.HandlerFunc {
 			return
 		}
 
-		cmd := command.CarStore{RegNums: req.RegNums}
-		cars, err := h.service.Store(&cmd)
+		cmd := command.CarStore{
+			RegNums:    req.RegNums,
+			OnConflict: command.CarStoreOnConflictError,
+		}
+		if req.OnConflict != nil {
+			cmd.OnConflict = *req.OnConflict
+		}
+		if req.Mode != nil && *req.Mode == request.CarStoreModePartial {
+			results, err := h.service.StorePartial(r.Context(), &cmd)
+			if err != nil {
+				log.Error("failed to create cars", slog.String("error", err.Error()))
+				response.Bad(&w, r, err)
+				return
+			}
+
+			log.Debug("created cars", slog.Any("results", results))
+
+			response.MultiStatus(&w, r, results)
+			return
+		}
+		cars, err := h.service.Store(r.Context(), &cmd)
 		if err != nil {
 			log.Error("failed to create cars", slog.String("error", err.Error()))
 			response.Bad(&w, r, err)
@@ -134,15 +251,20 @@ func (h *Handler) Store() http.HandlerFunc {
 
 // Update modifies an existing car
 // @Summary Update car details
-// @Description Update details of an existing car by its ID
+// @Description Update details of an existing car by its ID.
+// @Description If-Match must carry the strong ETag the car was read with, it may only be left out when HTTP_IF_MATCH_REQUIRED is turned off.
 // @Tags cars
 // @Accept json
 // @Produce json
 // @Param id path int true "Car ID"
+// @Param If-Match header string false "ETag of the car version the update is based on"
 // @Param request body request.CarUpdate true "Car update details"
 // @Success 200 {object} model.Car
+// @Header 200 {string} ETag "Version of the updated car"
 // @Failure 400 {object} response.Error
 // @Failure 404 {object} response.Error
+// @Failure 412 {object} response.Error
+// @Failure 428 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /api/cars/{id} [patch]
 func (h *Handler) Update() http.HandlerFunc {
@@ -181,7 +303,10 @@ func (h *Handler) Update() http.HandlerFunc {
 			Model:  req.Model,
 			Year:   req.Year,
 		}
-		car, err := h.service.Update(&cmd)
+		if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+			cmd.IfMatch = &ifMatch
+		}
+		car, err := h.service.Update(r.Context(), &cmd)
 		if err != nil {
 			log.Error("failed to update car", slog.String("error", err.Error()))
 			response.Bad(&w, r, err)
@@ -190,13 +315,14 @@ func (h *Handler) Update() http.HandlerFunc {
 
 		log.Debug("updated car", slog.Any("car", car))
 
+		response.ETag(&w, car.Version)
 		response.Ok(&w, r, car)
 	}
 }
 
 // Delete removes a car
 // @Summary Remove a car
-// @Description Delete a car by its ID
+// @Description Delete a car by its ID, it can be restored until purged
 // @Tags cars
 // @Accept json
 // @Produce json
@@ -225,7 +351,7 @@ func (h *Handler) Delete() http.HandlerFunc {
 			return
 		}
 		cmd.ID = id
-		if err = h.service.Delete(&cmd); err != nil {
+		if err = h.service.Delete(r.Context(), &cmd); err != nil {
 			log.Error("failed to delete car", slog.String("error", err.Error()))
 			response.Bad(&w, r, err)
 			return
@@ -236,3 +362,267 @@ func (h *Handler) Delete() http.HandlerFunc {
 		response.Ok(&w, r, nil)
 	}
 }
+
+// Restore brings back a deleted car
+// @Summary Restore a car
+// @Description Restore a deleted car by its ID unless its registration number has been taken since
+// @Tags cars
+// @Accept json
+// @Produce json
+// @Param id path int true "Car ID"
+// @Success 200 {object} model.Car
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api/cars/{id}/restore [post]
+func (h *Handler) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.car.Restore"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("restoring car")
+
+		idParam := chi.URLParam(r, "id")
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			log.Error("failed to convert", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		cmd := command.CarRestore{ID: id}
+		car, err := h.service.Restore(r.Context(), &cmd)
+		if err != nil {
+			log.Error("failed to restore car", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("restored car", slog.Any("car", car))
+
+		response.ETag(&w, car.Version)
+		response.Ok(&w, r, car)
+	}
+}
+
+// Transfer hands a car over to another owner
+// @Summary Transfer car ownership
+// @Description Close the current ownership of the car and open a new one for the given owner
+// @Tags cars
+// @Accept json
+// @Produce json
+// @Param id path int true "Car ID"
+// @Param request body request.CarTransfer true "New owner and transfer date, now by default, not in the future"
+// @Success 200 {object} model.Car
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 412 {object} response.Error "The car was changed while transferring it"
+// @Failure 500 {object} response.Error
+// @Router /api/cars/{id}/transfer [post]
+func (h *Handler) Transfer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.car.Transfer"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("transferring car")
+
+		var req request.CarTransfer
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			log.Error("failed to validate", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+		idParam := chi.URLParam(r, "id")
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			log.Error("failed to convert", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		cmd := command.CarTransfer{
+			ID:      id,
+			OwnerID: req.OwnerID,
+			Date:    req.Date,
+		}
+		car, err := h.service.Transfer(r.Context(), &cmd)
+		if err != nil {
+			log.Error("failed to transfer car", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("transferred car", slog.Any("car", car))
+
+		response.ETag(&w, car.Version)
+		response.Ok(&w, r, car)
+	}
+}
+
+// Owners returns the ownership timeline of a car
+// @Summary List car owners
+// @Description Get every ownership period of a car, oldest first
+// @Tags cars
+// @Accept json
+// @Produce json
+// @Param id path int true "Car ID"
+// @Success 200 {array} model.CarOwnership
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api/cars/{id}/owners [get]
+func (h *Handler) Owners() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.car.Owners"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("searching car owners")
+
+		idParam := chi.URLParam(r, "id")
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			log.Error("failed to convert", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		cmd := command.CarOwners{ID: id}
+		ownerships, err := h.service.Owners(r.Context(), &cmd)
+		if err != nil {
+			log.Error("failed to search car owners", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("searched car owners", slog.Any("ownerships", ownerships))
+
+		response.Ok(&w, r, ownerships)
+	}
+}
+
+// Owner returns the owner of a car at a point in time
+// @Summary Get car owner at a date
+// @Description Get the ownership period of a car covering the given date, the current one by default
+// @Tags cars
+// @Accept json
+// @Produce json
+// @Param id path int true "Car ID"
+// @Param at query string false "Date (2006-01-02, start of the day in UTC) or RFC 3339 time, now by default"
+// @Success 200 {object} model.CarOwnership
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api/cars/{id}/owner [get]
+func (h *Handler) Owner() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.car.Owner"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("searching car owner")
+
+		var req request.CarOwner
+		if err := schema.NewDecoder().Decode(&req, r.URL.Query()); err != nil {
+			log.Error("failed to decode", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+		idParam := chi.URLParam(r, "id")
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			log.Error("failed to convert", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		cmd := command.CarOwner{
+			ID: id,
+			At: req.At,
+		}
+		ownership, err := h.service.Owner(r.Context(), &cmd)
+		if err != nil {
+			log.Error("failed to search car owner", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("searched car owner", slog.Any("ownership", ownership))
+
+		response.Ok(&w, r, ownership)
+	}
+}
+
+// History returns the recorded changes of a car
+// @Summary List car history
+// @Description Get the recorded changes of a car, the latest first, also once the car is deleted.
+// @Description Before and after hold only the fields the change touched.
+// @Tags cars
+// @Accept json
+// @Produce json
+// @Param id path int true "Car ID"
+// @Param page query int false "Page number for pagination"
+// @Param count query int false "Number of items per page"
+// @Success 200 {object} response.Page{items=[]model.AuditLog}
+// @Header 200 {integer} X-Total-Count "Total number of changes of the car"
+// @Header 200 {string} Link "Links to the first, prev, next and last pages"
+// @Failure 400 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api/cars/{id}/history [get]
+func (h *Handler) History() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.car.History"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("searching car history")
+
+		var req request.CarHistory
+		if err := schema.NewDecoder().Decode(&req, r.URL.Query()); err != nil {
+			log.Error("failed to decode", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+		idParam := chi.URLParam(r, "id")
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			log.Error("failed to convert", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		cmd := command.CarHistory{
+			ID:    id,
+			Page:  req.Page,
+			Count: req.Count,
+		}
+		auditLogList, err := h.service.History(r.Context(), &cmd)
+		if err != nil {
+			log.Error("failed to search car history", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("searched car history", slog.Any("auditLogList", auditLogList))
+
+		response.Paginated(&w, r, auditLogList.Items, auditLogList.Pagination)
+	}
+}
@@ -1,13 +1,22 @@
 package car
 
 import (
+	"context"
+
 	"effective_mobile_2/internal/dto/command"
 	"effective_mobile_2/internal/dto/model"
 )
 
 type service interface {
-	Index(cmd *command.CarIndex) (*[]model.Car, error)
-	Store(cmd *command.CarStore) (*[]model.Car, error)
-	Update(cmd *command.CarUpdate) (*model.Car, error)
-	Delete(cmd *command.CarDelete) error
+	Index(ctx context.Context, cmd *command.CarIndex) (*model.CarList, error)
+	Show(ctx context.Context, cmd *command.CarShow) (*model.Car, error)
+	Store(ctx context.Context, cmd *command.CarStore) (*[]model.Car, error)
+	StorePartial(ctx context.Context, cmd *command.CarStore) (*[]model.CarStoreResult, error)
+	Update(ctx context.Context, cmd *command.CarUpdate) (*model.Car, error)
+	Delete(ctx context.Context, cmd *command.CarDelete) error
+	Restore(ctx context.Context, cmd *command.CarRestore) (*model.Car, error)
+	Transfer(ctx context.Context, cmd *command.CarTransfer) (*model.Car, error)
+	History(ctx context.Context, cmd *command.CarHistory) (*model.AuditLogList, error)
+	Owner(ctx context.Context, cmd *command.CarOwner) (*model.CarOwnership, error)
+	Owners(ctx context.Context, cmd *command.CarOwners) (*[]model.CarOwnership, error)
 }
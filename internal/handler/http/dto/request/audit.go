@@ -0,0 +1,13 @@
+package request
+
+type AuditIndex struct {
+	EntityType *string `schema:"entityType"`
+	EntityID   *uint   `schema:"entityID"`
+	Action     *string `schema:"action"`
+	Actor      *string `schema:"actor"`
+	RequestID  *string `schema:"requestID"`
+	From       *string `schema:"from"`
+	To         *string `schema:"to"`
+	Page       *int    `schema:"page"`
+	Count      *int    `schema:"count"`
+}
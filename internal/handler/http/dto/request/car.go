@@ -1,19 +1,45 @@
 package request
 
+import "time"
+
 type CarIndex struct {
-	RegNum       *string `schema:"regNum"`
-	Mark         *string `schema:"mark"`
-	Model        *string `schema:"model"`
-	Year         *int    `schema:"year"`
-	OwnerName    *string `schema:"ownerName"`
-	OwnerSurname *string `schema:"ownerSurname"`
-	Order        *string `schema:"order"`
-	Page         *int    `schema:"page"`
-	Count        *int    `schema:"count"`
+	RegNum          *string  `schema:"regNum"`
+	RegNumPrefix    *string  `schema:"regNumPrefix"`
+	Mark            []string `schema:"mark"`
+	MarkMatch       *string  `schema:"markMatch"`
+	Model           *string  `schema:"model"`
+	Year            *int     `schema:"year"`
+	YearFrom        *int     `schema:"yearFrom"`
+	YearTo          *int     `schema:"yearTo"`
+	OwnerID         *uint    `schema:"ownerID"`
+	OwnedAt         *string  `schema:"ownedAt"`
+	OwnerName       *string  `schema:"ownerName"`
+	OwnerSurname    *string  `schema:"ownerSurname"`
+	OwnerPatronymic *string  `schema:"ownerPatronymic"`
+	OwnerMatch      *string  `schema:"ownerMatch"`
+	HasPatronymic   *bool    `schema:"hasPatronymic"`
+	Q               *string  `schema:"q"`
+	CreatedFrom     *string  `schema:"createdFrom"`
+	CreatedTo       *string  `schema:"createdTo"`
+	UpdatedSince    *string  `schema:"updatedSince"`
+	IncludeDeleted  *bool    `schema:"includeDeleted"`
+	OnlyDeleted     *bool    `schema:"onlyDeleted"`
+	Order           *string  `schema:"order"`
+	Sort            *string  `schema:"sort"`
+	Page            *int     `schema:"page"`
+	Count           *int     `schema:"count"`
+	Cursor          *string  `schema:"cursor"`
 }
 
+const (
+	CarStoreModeAtomic  = "atomic"
+	CarStoreModePartial = "partial"
+)
+
 type CarStore struct {
-	RegNums []string `json:"regNums" validate:"required,min=1,dive,required"`
+	RegNums    []string `json:"regNums" validate:"required,min=1,dive,required"`
+	Mode       *string  `json:"mode" validate:"omitempty,oneof=atomic partial"`
+	OnConflict *string  `json:"onConflict" validate:"omitempty,oneof=error skip refresh"`
 }
 
 type CarUpdate struct {
@@ -22,3 +48,17 @@ type CarUpdate struct {
 	Model  *string `json:"model" validate:"omitempty,ne="`
 	Year   *int    `json:"year" validate:"omitempty,gte=1886,lte=2023"`
 }
+
+type CarHistory struct {
+	Page  *int `schema:"page"`
+	Count *int `schema:"count"`
+}
+
+type CarOwner struct {
+	At *string `schema:"at"`
+}
+
+type CarTransfer struct {
+	OwnerID uint       `json:"ownerID" validate:"required"`
+	Date    *time.Time `json:"date"`
+}
@@ -0,0 +1,32 @@
+package request
+
+type PeopleIndex struct {
+	Name       *string `schema:"name"`
+	Surname    *string `schema:"surname"`
+	Patronymic *string `schema:"patronymic"`
+	Order      *string `schema:"order"`
+	Page       *int    `schema:"page"`
+	Count      *int    `schema:"count"`
+}
+
+type PeopleStore struct {
+	Name       string  `json:"name" validate:"required"`
+	Surname    string  `json:"surname" validate:"required"`
+	Patronymic *string `json:"patronymic" validate:"omitempty,ne="`
+}
+
+type PeopleUpdate struct {
+	Name       *string `json:"name" validate:"omitempty,ne="`
+	Surname    *string `json:"surname" validate:"omitempty,ne="`
+	Patronymic *string `json:"patronymic" validate:"omitempty,ne="`
+}
+
+type PeopleMerge struct {
+	DuplicateIDs []int `json:"duplicateIDs" validate:"required,min=1,unique,dive,gt=0"`
+	DryRun       bool  `json:"dryRun"`
+}
+
+type PeopleDuplicates struct {
+	Page  *int `schema:"page"`
+	Count *int `schema:"count"`
+}
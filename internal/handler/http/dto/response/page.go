@@ -0,0 +1,76 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"effective_mobile_2/internal/dto/model"
+)
+
+type Page struct {
+	Items interface{} `json:"items"`
+	model.Pagination
+	Next *string `json:"next"`
+	Prev *string `json:"prev"`
+}
+
+type CursorPage struct {
+	Items interface{} `json:"items"`
+	model.Cursor
+}
+
+// Paginated writes items wrapped into a Page envelope and mirrors
+// the pagination into X-Total-Count and Link headers.
+func Paginated(w *http.ResponseWriter, r *http.Request, items interface{}, pagination model.Pagination) {
+	page := Page{Items: items, Pagination: pagination}
+	links := make([]string, 0, 4)
+
+	if pagination.TotalPages > 0 {
+		links = append(links, fmt.Sprintf(`<%s>; rel="first"`, pageURL(r, 1)))
+	}
+	if pagination.Page > 1 && pagination.TotalPages > 0 {
+		prev := pageURL(r, min(pagination.Page-1, pagination.TotalPages))
+		page.Prev = &prev
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, prev))
+	}
+	if pagination.Page < pagination.TotalPages {
+		next := pageURL(r, pagination.Page+1)
+		page.Next = &next
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, next))
+	}
+	if pagination.TotalPages > 0 {
+		links = append(links, fmt.Sprintf(`<%s>; rel="last"`, pageURL(r, pagination.TotalPages)))
+	}
+
+	(*w).Header().Set("X-Total-Count", strconv.FormatInt(pagination.Total, 10))
+	if len(links) > 0 {
+		(*w).Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	Ok(w, r, page)
+}
+
+// Scrolled writes items wrapped into a CursorPage envelope and links
+// the next page through the Link header.
+func Scrolled(w *http.ResponseWriter, r *http.Request, items interface{}, cursor model.Cursor) {
+	if cursor.NextCursor != nil {
+		values := r.URL.Query()
+		values.Set("cursor", *cursor.NextCursor)
+		link := url.URL{Path: r.URL.Path, RawQuery: values.Encode()}
+		(*w).Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, link.String()))
+	}
+
+	Ok(w, r, CursorPage{Items: items, Cursor: cursor})
+}
+
+func pageURL(r *http.Request, page int) string {
+	values := r.URL.Query()
+	values.Set("page", strconv.Itoa(page))
+
+	link := url.URL{Path: r.URL.Path, RawQuery: values.Encode()}
+
+	return link.String()
+}
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"strconv"
 
 	"effective_mobile_2/internal/app_error"
 	"github.com/go-chi/render"
@@ -23,6 +25,25 @@ func Bad(w *http.ResponseWriter, r *http.Request, err error) {
 	case errors.Is(err, app_error.ErrNotFound):
 		code = http.StatusNotFound
 		message = err.Error()
+	case errors.Is(err, app_error.ErrInvalidArgument):
+		code = http.StatusBadRequest
+		message = err.Error()
+	case errors.Is(err, app_error.ErrConflict):
+		code = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, app_error.ErrUnavailable):
+		code = http.StatusServiceUnavailable
+		message = err.Error()
+		if after, ok := app_error.RetryAfter(err); ok {
+			seconds := int64(math.Ceil(after.Seconds()))
+			(*w).Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+		}
+	case errors.Is(err, app_error.ErrPreconditionFailed):
+		code = http.StatusPreconditionFailed
+		message = err.Error()
+	case errors.Is(err, app_error.ErrPreconditionRequired):
+		code = http.StatusPreconditionRequired
+		message = err.Error()
 	case errors.As(err, &validator.ValidationErrors{}):
 		var ve validator.ValidationErrors
 		if errors.As(err, &ve) {
@@ -43,6 +64,16 @@ func Bad(w *http.ResponseWriter, r *http.Request, err error) {
 	render.JSON(*w, r, Error{Message: message})
 }
 
+// ETag sets the ETag header to the version of the returned entity.
+func ETag(w *http.ResponseWriter, version uint) {
+	(*w).Header().Set("ETag", strconv.Quote(strconv.FormatUint(uint64(version), 10)))
+}
+
+func MultiStatus(w *http.ResponseWriter, r *http.Request, data interface{}) {
+	(*w).WriteHeader(http.StatusMultiStatus)
+	render.JSON(*w, r, data)
+}
+
 func Ok(w *http.ResponseWriter, r *http.Request, data interface{}) {
 	(*w).WriteHeader(http.StatusOK)
 	render.JSON(*w, r, data)
@@ -0,0 +1,50 @@
+package health
+
+import (
+	"log/slog"
+	"net/http"
+
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/handler/http/dto/response"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type Handler struct {
+	service service
+}
+
+func New(service service) *Handler {
+	return &Handler{service: service}
+}
+
+// Show returns the health of the service
+// @Summary Get service health
+// @Description Get the service status and the circuit breaker states of the car info providers
+// @Tags health
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.Health
+// @Failure 500 {object} response.Error
+// @Router /api/health [get]
+func (h *Handler) Show() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.health.Show"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("checking health")
+
+		health, err := h.service.Show(r.Context())
+		if err != nil {
+			log.Error("failed to check health", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("checked health", slog.Any("health", health))
+
+		response.Ok(&w, r, health)
+	}
+}
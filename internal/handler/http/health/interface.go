@@ -0,0 +1,11 @@
+package health
+
+import (
+	"context"
+
+	"effective_mobile_2/internal/dto/model"
+)
+
+type service interface {
+	Show(ctx context.Context) (*model.Health, error)
+}
@@ -0,0 +1,383 @@
+package people
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/dto/command"
+	"effective_mobile_2/internal/handler/http/dto/request"
+	"effective_mobile_2/internal/handler/http/dto/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/schema"
+)
+
+type Handler struct {
+	service service
+}
+
+func New(service service) *Handler {
+	return &Handler{service: service}
+}
+
+// Index lists all peoples based on query parameters
+// @Summary List all peoples
+// @Description Get a list of car owners filtered by various parameters
+// @Tags peoples
+// @Accept json
+// @Produce json
+// @Param name query string false "Name filter, case and accent insensitive substring"
+// @Param surname query string false "Surname filter, case and accent insensitive substring"
+// @Param patronymic query string false "Patronymic filter, case and accent insensitive substring"
+// @Param order query string false "Order of results (asc or desc)"
+// @Param page query int false "Page number for pagination"
+// @Param count query int false "Number of items per page"
+// @Success 200 {array} model.People
+// @Failure 400 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api/people [get]
+func (h *Handler) Index() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.people.Index"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("searching peoples")
+
+		var req request.PeopleIndex
+		if err := schema.NewDecoder().Decode(&req, r.URL.Query()); err != nil {
+			log.Error("failed to decode", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		cmd := command.PeopleIndex{
+			Name:       req.Name,
+			Surname:    req.Surname,
+			Patronymic: req.Patronymic,
+			Order:      req.Order,
+			Page:       req.Page,
+			Count:      req.Count,
+		}
+		peoples, err := h.service.Index(r.Context(), &cmd)
+		if err != nil {
+			log.Error("failed to search peoples", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("searched peoples", slog.Any("peoples", peoples))
+
+		response.Ok(&w, r, peoples)
+	}
+}
+
+// Show returns a single people
+// @Summary Get people details
+// @Description Get a car owner by its ID
+// @Tags peoples
+// @Accept json
+// @Produce json
+// @Param id path int true "People ID"
+// @Success 200 {object} model.People
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api/people/{id} [get]
+func (h *Handler) Show() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.people.Show"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("searching people")
+
+		idParam := chi.URLParam(r, "id")
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			log.Error("failed to convert", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		cmd := command.PeopleShow{ID: id}
+		people, err := h.service.Show(r.Context(), &cmd)
+		if err != nil {
+			log.Error("failed to search people", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("searched people", slog.Any("people", people))
+
+		response.Ok(&w, r, people)
+	}
+}
+
+// Store creates a new people based on the provided data
+// @Summary Create new people
+// @Description Add a new car owner to the database
+// @Tags peoples
+// @Accept json
+// @Produce json
+// @Param request body request.PeopleStore true "New people details"
+// @Success 200 {object} model.People
+// @Failure 400 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api/people [post]
+func (h *Handler) Store() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.people.Store"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("creating people")
+
+		var req request.PeopleStore
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			log.Error("failed to validate", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		cmd := command.PeopleStore{
+			Name:       req.Name,
+			Surname:    req.Surname,
+			Patronymic: req.Patronymic,
+		}
+		people, err := h.service.Store(r.Context(), &cmd)
+		if err != nil {
+			log.Error("failed to create people", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("created people", slog.Any("people", people))
+
+		response.Ok(&w, r, people)
+	}
+}
+
+// Update modifies an existing people
+// @Summary Update people details
+// @Description Update details of an existing car owner by its ID
+// @Tags peoples
+// @Accept json
+// @Produce json
+// @Param id path int true "People ID"
+// @Param request body request.PeopleUpdate true "People update details"
+// @Success 200 {object} model.People
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api/people/{id} [patch]
+func (h *Handler) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.people.Update"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("updating people")
+
+		var req request.PeopleUpdate
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			log.Error("failed to validate", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+		idParam := chi.URLParam(r, "id")
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			log.Error("failed to convert", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		cmd := command.PeopleUpdate{
+			ID:         id,
+			Name:       req.Name,
+			Surname:    req.Surname,
+			Patronymic: req.Patronymic,
+		}
+		people, err := h.service.Update(r.Context(), &cmd)
+		if err != nil {
+			log.Error("failed to update people", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("updated people", slog.Any("people", people))
+
+		response.Ok(&w, r, people)
+	}
+}
+
+// Delete removes a people
+// @Summary Remove a people
+// @Description Delete a car owner by its ID, peoples that own or owned cars cannot be deleted
+// @Tags peoples
+// @Accept json
+// @Produce json
+// @Param id path int true "People ID"
+// @Success 200
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 409 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api/people/{id} [delete]
+func (h *Handler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.people.Delete"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("deleting people")
+
+		var cmd command.PeopleDelete
+		idParam := chi.URLParam(r, "id")
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			log.Error("failed to convert", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+		cmd.ID = id
+		if err = h.service.Delete(r.Context(), &cmd); err != nil {
+			log.Error("failed to delete people", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("deleted people")
+
+		response.Ok(&w, r, nil)
+	}
+}
+
+// Merge consolidates duplicate peoples into one
+// @Summary Merge duplicate peoples
+// @Description Reassign all cars of the duplicates to the target people and delete the duplicates in one transaction.
+// @Description With dryRun the changes are rolled back and only the summary is returned.
+// @Tags peoples
+// @Accept json
+// @Produce json
+// @Param id path int true "Target people ID"
+// @Param request body request.PeopleMerge true "Duplicates to merge"
+// @Success 200 {object} model.PeopleMerge
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api/people/{id}/merge [post]
+func (h *Handler) Merge() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.people.Merge"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("merging peoples")
+
+		var req request.PeopleMerge
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("failed to decode", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+		if err := validator.New().Struct(req); err != nil {
+			log.Error("failed to validate", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+		idParam := chi.URLParam(r, "id")
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			log.Error("failed to convert", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		cmd := command.PeopleMerge{
+			ID:           id,
+			DuplicateIDs: req.DuplicateIDs,
+			DryRun:       req.DryRun,
+		}
+		merge, err := h.service.Merge(r.Context(), &cmd)
+		if err != nil {
+			log.Error("failed to merge peoples", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("merged peoples", slog.Any("merge", merge))
+
+		response.Ok(&w, r, merge)
+	}
+}
+
+// Duplicates lists suspected duplicate peoples
+// @Summary List suspected duplicates
+// @Description Get groups of peoples sharing the same normalized full name, largest groups first
+// @Tags peoples
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number for pagination"
+// @Param count query int false "Number of groups per page"
+// @Success 200 {array} model.PeopleDuplicate
+// @Failure 400 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /api/people/duplicates [get]
+func (h *Handler) Duplicates() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handler.http.people.Duplicates"
+		log := app_log.Logger().With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		log.Info("searching duplicates")
+
+		var req request.PeopleDuplicates
+		if err := schema.NewDecoder().Decode(&req, r.URL.Query()); err != nil {
+			log.Error("failed to decode", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		cmd := command.PeopleDuplicates{Page: req.Page, Count: req.Count}
+		duplicates, err := h.service.Duplicates(r.Context(), &cmd)
+		if err != nil {
+			log.Error("failed to search duplicates", slog.String("error", err.Error()))
+			response.Bad(&w, r, err)
+			return
+		}
+
+		log.Debug("searched duplicates", slog.Any("duplicates", duplicates))
+
+		response.Ok(&w, r, duplicates)
+	}
+}
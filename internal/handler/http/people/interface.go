@@ -0,0 +1,18 @@
+package people
+
+import (
+	"context"
+
+	"effective_mobile_2/internal/dto/command"
+	"effective_mobile_2/internal/dto/model"
+)
+
+type service interface {
+	Index(ctx context.Context, cmd *command.PeopleIndex) (*[]model.People, error)
+	Show(ctx context.Context, cmd *command.PeopleShow) (*model.People, error)
+	Store(ctx context.Context, cmd *command.PeopleStore) (*model.People, error)
+	Update(ctx context.Context, cmd *command.PeopleUpdate) (*model.People, error)
+	Delete(ctx context.Context, cmd *command.PeopleDelete) error
+	Merge(ctx context.Context, cmd *command.PeopleMerge) (*model.PeopleMerge, error)
+	Duplicates(ctx context.Context, cmd *command.PeopleDuplicates) (*[]model.PeopleDuplicate, error)
+}
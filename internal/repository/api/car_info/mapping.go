@@ -0,0 +1,100 @@
+package car_info
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"effective_mobile_2/internal/app_mapping"
+	"effective_mobile_2/internal/dto/model"
+)
+
+// carInfoPaths are the paths of the car info fields a mapping can point
+// elsewhere.
+var carInfoPaths = []string{
+	"mark",
+	"model",
+	"year",
+	"owner.name",
+	"owner.surname",
+	"owner.patronymic",
+	"owner.externalID",
+}
+
+// compile returns the field of every car info path, read as the mapping
+// tells or from its own path when it is not mapped, and normalized at the
+// end: the year to a year and the other fields to strings. It returns nil
+// without a mapping.
+func compile(mapping app_mapping.Mapping) (map[string]app_mapping.Field, error) {
+	if len(mapping) == 0 {
+		return nil, nil
+	}
+
+	fields := make(map[string]app_mapping.Field, len(carInfoPaths))
+	for _, path := range carInfoPaths {
+		field, ok := mapping[path]
+		if !ok {
+			field = app_mapping.Field{Path: path}
+		}
+		normalize := "string"
+		if path == model.CarInfoFieldYear {
+			normalize = "year"
+		}
+		field.Transforms = append(slices.Clip(field.Transforms), normalize)
+		if err := field.Compile(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		fields[path] = field
+	}
+
+	return fields, nil
+}
+
+// mapCarInfo reads the car info from a response body. Without a mapping
+// the body is the car info itself; otherwise every car info field is read
+// by its compiled field. Numbers are decoded exactly, so large IDs survive.
+func (r *Repository) mapCarInfo(body []byte) (*model.CarInfo, error) {
+	carInfo := model.CarInfo{}
+	if len(r.fields) == 0 {
+		if err := json.Unmarshal(body, &carInfo); err != nil {
+			return nil, err
+		}
+		return &carInfo, nil
+	}
+
+	var document any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	values := make(map[string]any, len(carInfoPaths))
+	for _, path := range carInfoPaths {
+		value, err := r.fields[path].Value(document)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if value != nil {
+			values[path] = value
+		}
+	}
+	carInfo.Mark, _ = values["mark"].(string)
+	carInfo.Model, _ = values["model"].(string)
+	if year, ok := values["year"].(int); ok {
+		carInfo.Year = &year
+	}
+	name, hasName := values["owner.name"].(string)
+	surname, hasSurname := values["owner.surname"].(string)
+	if hasName || hasSurname {
+		carInfo.Owner = &model.People{Name: name, Surname: surname}
+		if patronymic, ok := values["owner.patronymic"].(string); ok {
+			carInfo.Owner.Patronymic = &patronymic
+		}
+		if externalID, ok := values["owner.externalID"].(string); ok {
+			carInfo.Owner.ExternalID = &externalID
+		}
+	}
+
+	return &carInfo, nil
+}
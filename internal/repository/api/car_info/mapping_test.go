@@ -0,0 +1,65 @@
+package car_info_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/app_mapping"
+	"effective_mobile_2/internal/dto/query"
+	"effective_mobile_2/internal/repository/api/car_info"
+)
+
+func TestGetCarInfoMapping(t *testing.T) {
+	app_log.Setup("error")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"vehicle": {"brand": "lada", "model": "vesta", "made": "05/98"},
+			"persons": [
+				{"role": "seller", "name": "Petr Petrov", "id": 1},
+				{"role": "owner", "name": "Ivan Ivanov", "id": 9007199254740993}
+			]
+		}`))
+	}))
+	t.Cleanup(server.Close)
+	mapping := app_mapping.Mapping{
+		"mark":             {Path: "$.vehicle.brand", Transforms: []string{"title"}},
+		"model":            {Path: "$.vehicle.model", Transforms: []string{"title"}},
+		"year":             {Path: "$.vehicle.made"},
+		"owner.name":       {Path: "$.persons[?(@.role == 'owner')].name", Transforms: []string{"word:0"}},
+		"owner.surname":    {Path: "$.persons[?(@.role == 'owner')].name", Transforms: []string{"word:-1"}},
+		"owner.externalID": {Path: "$.persons[?(@.role == 'owner')].id"},
+	}
+	repository := newRepository(t, server.URL, car_info.Options{Timeout: time.Second, Mapping: mapping})
+
+	carInfo, err := repository.GetCarInfo(context.Background(), &query.CarInfo{RegNum: "X123XX150"})
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	if carInfo.Mark != "Lada" || carInfo.Model != "Vesta" {
+		t.Errorf("got %s %s, want Lada Vesta", carInfo.Mark, carInfo.Model)
+	}
+	if carInfo.Year == nil || *carInfo.Year != 1998 {
+		t.Errorf("got year %v, want 1998", carInfo.Year)
+	}
+	if carInfo.Owner == nil || carInfo.Owner.Name != "Ivan" || carInfo.Owner.Surname != "Ivanov" {
+		t.Fatalf("got owner %+v, want Ivan Ivanov", carInfo.Owner)
+	}
+	if carInfo.Owner.Patronymic != nil {
+		t.Errorf("got patronymic %q, want none", *carInfo.Owner.Patronymic)
+	}
+	if carInfo.Owner.ExternalID == nil || *carInfo.Owner.ExternalID != "9007199254740993" {
+		t.Errorf("got external id %v, want 9007199254740993", carInfo.Owner.ExternalID)
+	}
+}
+
+func TestNewRejectsBadMapping(t *testing.T) {
+	mapping := app_mapping.Mapping{"year": {Path: "$.made", Transforms: []string{"yearly"}}}
+	if _, err := car_info.New("http://localhost", car_info.Options{Mapping: mapping}); err == nil {
+		t.Error("got no error for a bad mapping")
+	}
+}
@@ -1,28 +1,83 @@
 package car_info
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"effective_mobile_2/internal/app_error"
 	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/app_mapping"
 	"effective_mobile_2/internal/dto/model"
 	"effective_mobile_2/internal/dto/query"
 )
 
+const (
+	AuthBearer = "bearer"
+	AuthBasic  = "basic"
+	AuthHeader = "header"
+)
+
+// defaultPath is where the car info is asked for when Options.Path is
+// empty, {regNum} is replaced by the reg number.
+const defaultPath = "/info?regNum={regNum}"
+
+// Options tune the HTTP client of the Repository: Timeout bounds every
+// attempt, and failed attempts are retried Retries times after a backoff
+// starting at Backoff and capped at MaxBackoff. Path, Auth and Mapping
+// describe the provider API.
+type Options struct {
+	Timeout    time.Duration
+	Retries    int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Path       string
+	Auth       Auth
+	Mapping    app_mapping.Mapping
+}
+
+// Auth is sent with every request: a bearer Token, a basic Username and
+// Password or a Token in Header, depending on Type. An empty Type sends
+// nothing.
+type Auth struct {
+	Type     string
+	Header   string
+	Token    string
+	Username string
+	Password string
+}
+
 type Repository struct {
-	url string
+	url     string
+	client  *http.Client
+	options Options
+	fields  map[string]app_mapping.Field
 }
 
-func New(url string) *Repository {
-	return &Repository{url: strings.TrimRight(url, "/")}
+// New fails when Options.Mapping does not compile.
+func New(url string, options Options) (*Repository, error) {
+	if options.Path == "" {
+		options.Path = defaultPath
+	}
+	fields, err := compile(options.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Repository{
+		url:     strings.TrimRight(url, "/"),
+		client:  &http.Client{Timeout: options.Timeout},
+		options: options,
+		fields:  fields,
+	}, nil
 }
 
-func (r *Repository) GetCarInfo(qry *query.CarInfo) (*model.CarInfo, error) {
+func (r *Repository) GetCarInfo(ctx context.Context, qry *query.CarInfo) (*model.CarInfo, error) {
 	const op = "repository.api.car_info.GetCarInfo"
 	log := app_log.Logger().With(
 		slog.String("op", op),
@@ -31,14 +86,8 @@ func (r *Repository) GetCarInfo(qry *query.CarInfo) (*model.CarInfo, error) {
 
 	log.Info("getting car info")
 
-	req, err := http.NewRequest("GET", r.url+"/info?regNum="+qry.RegNum, nil)
-	if err != nil {
-		log.Error("failed to create HTTP request", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("%w: %w", app_error.ErrHTTPRequestFailed, err)
-	}
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	path := strings.ReplaceAll(r.options.Path, "{regNum}", url.QueryEscape(qry.RegNum))
+	resp, err := r.do(ctx, r.url+path)
 	if err != nil {
 		log.Error("HTTP request failed", slog.String("error", err.Error()))
 		return nil, fmt.Errorf("%w: %w", app_error.ErrHTTPRequestFailed, err)
@@ -60,13 +109,25 @@ func (r *Repository) GetCarInfo(qry *query.CarInfo) (*model.CarInfo, error) {
 		return nil, fmt.Errorf("%w: %w", app_error.ErrHTTPRequestFailed, err)
 	}
 
-	carInfo := model.CarInfo{}
-	if err = json.Unmarshal(responseBody, &carInfo); err != nil {
+	carInfo, err := r.mapCarInfo(responseBody)
+	if err != nil {
 		log.Error("JSON unmarshaling failed", slog.String("error", err.Error()))
 		return nil, fmt.Errorf("%w: %w", app_error.ErrHTTPRequestFailed, err)
 	}
 
 	log.Debug("got car info", slog.Any("carInfo", carInfo))
 
-	return &carInfo, nil
+	return carInfo, nil
+}
+
+func (r *Repository) authorize(req *http.Request) {
+	auth := r.options.Auth
+	switch auth.Type {
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+auth.Token)
+	case AuthBasic:
+		req.SetBasicAuth(auth.Username, auth.Password)
+	case AuthHeader:
+		req.Header.Set(auth.Header, auth.Token)
+	}
 }
@@ -0,0 +1,86 @@
+package car_info
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// do sends a GET to url, retrying network errors, 5xx and 429 responses up
+// to Options.Retries times. The waits grow exponentially from
+// Options.Backoff with full jitter, capped at Options.MaxBackoff, unless the
+// response says how long to wait in Retry-After. A Retry-After beyond
+// Options.MaxBackoff is not waited for, the response is returned instead,
+// as is the last response or error.
+func (r *Repository) do(ctx context.Context, url string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		r.authorize(req)
+		resp, err := r.client.Do(req)
+		if attempt >= r.options.Retries || ctx.Err() != nil {
+			return resp, err
+		}
+		if err == nil && !retryable(resp.StatusCode) {
+			return resp, nil
+		}
+
+		wait := r.backoff(attempt)
+		if err == nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				if retryAfter > r.options.MaxBackoff {
+					return resp, nil
+				}
+				wait = retryAfter
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func retryable(status int) bool {
+	return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+}
+
+// backoff returns a random wait up to Options.Backoff doubled attempt times.
+func (r *Repository) backoff(attempt int) time.Duration {
+	ceiling := r.options.MaxBackoff
+	if attempt < 32 {
+		ceiling = min(r.options.Backoff<<attempt, r.options.MaxBackoff)
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
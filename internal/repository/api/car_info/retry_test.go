@@ -0,0 +1,204 @@
+package car_info_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/dto/query"
+	"effective_mobile_2/internal/repository/api/car_info"
+)
+
+const carInfoJSON = `{"mark":"Lada","model":"Vesta","year":2002,"owner":{"name":"Ivan","surname":"Ivanov"}}`
+
+// response is what the stand-in provider answers to one attempt.
+type response struct {
+	status     int
+	retryAfter string
+}
+
+// serve starts a provider answering the attempts with responses in turn,
+// repeating the last one, and counts the attempts.
+func serve(t *testing.T, responses ...response) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := int(attempts.Add(1)) - 1
+		resp := responses[min(attempt, len(responses)-1)]
+		if resp.retryAfter != "" {
+			w.Header().Set("Retry-After", resp.retryAfter)
+		}
+		w.WriteHeader(resp.status)
+		if resp.status == http.StatusOK {
+			_, _ = w.Write([]byte(carInfoJSON))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &attempts
+}
+
+func newRepository(t *testing.T, url string, options car_info.Options) *car_info.Repository {
+	t.Helper()
+
+	repository, err := car_info.New(url, options)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	return repository
+}
+
+func TestGetCarInfoRetries(t *testing.T) {
+	app_log.Setup("error")
+
+	tests := []struct {
+		name      string
+		responses []response
+		retries   int
+		wantErr   error
+		attempts  int32
+	}{
+		{"ok", []response{{status: http.StatusOK}}, 2, nil, 1},
+		{"5xx retried", []response{{status: http.StatusInternalServerError}, {status: http.StatusBadGateway}, {status: http.StatusOK}}, 2, nil, 3},
+		{"429 retried", []response{{status: http.StatusTooManyRequests}, {status: http.StatusOK}}, 2, nil, 2},
+		{"4xx not retried", []response{{status: http.StatusBadRequest}, {status: http.StatusOK}}, 2, app_error.ErrHTTPRequestFailed, 1},
+		{"404 not retried", []response{{status: http.StatusNotFound}, {status: http.StatusOK}}, 2, app_error.ErrNotFound, 1},
+		{"retries exhausted", []response{{status: http.StatusServiceUnavailable}}, 2, app_error.ErrHTTPRequestFailed, 3},
+		{"no retries", []response{{status: http.StatusServiceUnavailable}, {status: http.StatusOK}}, 0, app_error.ErrHTTPRequestFailed, 1},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server, attempts := serve(t, tt.responses...)
+			repository := newRepository(t, server.URL, car_info.Options{
+				Timeout:    time.Second,
+				Retries:    tt.retries,
+				Backoff:    time.Millisecond,
+				MaxBackoff: 10 * time.Millisecond,
+			})
+
+			carInfo, err := repository.GetCarInfo(context.Background(), &query.CarInfo{RegNum: "X123XX150"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && carInfo.Mark != "Lada" {
+				t.Errorf("got mark %q, want Lada", carInfo.Mark)
+			}
+			if got := attempts.Load(); got != tt.attempts {
+				t.Errorf("got %d attempts, want %d", got, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestGetCarInfoRetryAfter(t *testing.T) {
+	app_log.Setup("error")
+
+	tests := []struct {
+		name       string
+		retryAfter string
+		maxBackoff time.Duration
+		wantErr    error
+		attempts   int32
+		minElapsed time.Duration
+		maxElapsed time.Duration
+	}{
+		{"waited for", "1", 2 * time.Second, nil, 2, time.Second, 2 * time.Second},
+		{"beyond max backoff", "3600", 10 * time.Millisecond, app_error.ErrHTTPRequestFailed, 1, 0, time.Second},
+		{"date beyond max backoff", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), 10 * time.Millisecond, app_error.ErrHTTPRequestFailed, 1, 0, time.Second},
+		{"invalid ignored", "soon", 10 * time.Millisecond, nil, 2, 0, time.Second},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server, attempts := serve(t, response{status: http.StatusServiceUnavailable, retryAfter: tt.retryAfter}, response{status: http.StatusOK})
+			repository := newRepository(t, server.URL, car_info.Options{
+				Timeout:    time.Second,
+				Retries:    1,
+				Backoff:    time.Millisecond,
+				MaxBackoff: tt.maxBackoff,
+			})
+
+			start := time.Now()
+			_, err := repository.GetCarInfo(context.Background(), &query.CarInfo{RegNum: "X123XX150"})
+			elapsed := time.Since(start)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if got := attempts.Load(); got != tt.attempts {
+				t.Errorf("got %d attempts, want %d", got, tt.attempts)
+			}
+			if elapsed < tt.minElapsed || elapsed > tt.maxElapsed {
+				t.Errorf("took %s, want between %s and %s", elapsed, tt.minElapsed, tt.maxElapsed)
+			}
+		})
+	}
+}
+
+func TestGetCarInfoTimeout(t *testing.T) {
+	app_log.Setup("error")
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	t.Cleanup(server.Close)
+	repository := newRepository(t, server.URL, car_info.Options{
+		Timeout:    20 * time.Millisecond,
+		Retries:    1,
+		Backoff:    time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+	})
+
+	start := time.Now()
+	_, err := repository.GetCarInfo(context.Background(), &query.CarInfo{RegNum: "X123XX150"})
+	if !errors.Is(err, app_error.ErrHTTPRequestFailed) {
+		t.Fatalf("got error %v, want %v", err, app_error.ErrHTTPRequestFailed)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("got %d attempts, want 2", got)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("took %s, want the attempts cut by the timeout", elapsed)
+	}
+}
+
+func TestGetCarInfoCanceled(t *testing.T) {
+	app_log.Setup("error")
+
+	server, attempts := serve(t, response{status: http.StatusServiceUnavailable, retryAfter: "1"})
+	repository := newRepository(t, server.URL, car_info.Options{
+		Timeout:    time.Second,
+		Retries:    3,
+		Backoff:    time.Millisecond,
+		MaxBackoff: 2 * time.Second,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := repository.GetCarInfo(ctx, &query.CarInfo{RegNum: "X123XX150"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("got %d attempts, want 1", got)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("took %s, want the wait cut by the context", elapsed)
+	}
+}
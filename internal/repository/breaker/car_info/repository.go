@@ -0,0 +1,164 @@
+package car_info
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+)
+
+type carInfoRepository interface {
+	GetCarInfo(ctx context.Context, qry *query.CarInfo) (*model.CarInfo, error)
+}
+
+// Options tune the breaker: Failures consecutive failures open it, after
+// OpenTimeout it lets Probes calls through and closes once they all succeed.
+type Options struct {
+	Failures    int
+	OpenTimeout time.Duration
+	Probes      int
+}
+
+// Repository is a circuit breaker in front of a car info repository. While
+// open it fails fast with app_error.ErrUnavailable instead of calling the
+// provider. Only provider failures count, a car that is not found does not.
+type Repository struct {
+	next    carInfoRepository
+	options Options
+
+	mu        sync.Mutex
+	state     string
+	failures  int
+	openedAt  time.Time
+	probes    int
+	successes int
+}
+
+func New(next carInfoRepository, options Options) *Repository {
+	options.Failures = max(options.Failures, 1)
+	options.Probes = max(options.Probes, 1)
+
+	return &Repository{
+		next:    next,
+		options: options,
+		state:   model.CircuitBreakerClosed,
+	}
+}
+
+func (r *Repository) GetCarInfo(ctx context.Context, qry *query.CarInfo) (*model.CarInfo, error) {
+	const op = "repository.breaker.car_info.GetCarInfo"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	if err := r.acquire(); err != nil {
+		log.Error("circuit breaker is open", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	carInfo, err := r.next.GetCarInfo(ctx, qry)
+	if ctx.Err() != nil {
+		r.cancel()
+		return carInfo, err
+	}
+	if state := r.release(errors.Is(err, app_error.ErrHTTPRequestFailed)); state != "" {
+		log.Info("circuit breaker changed state", slog.String("state", state))
+	}
+
+	return carInfo, err
+}
+
+// State reports the current state of the breaker.
+func (r *Repository) State() model.CircuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	breaker := model.CircuitBreaker{State: r.state, Failures: r.failures}
+	if r.state != model.CircuitBreakerClosed {
+		openedAt := r.openedAt
+		breaker.OpenedAt = &openedAt
+	}
+
+	return breaker
+}
+
+// acquire lets a call through unless the breaker is open, moving it to
+// half-open once OpenTimeout has passed.
+func (r *Repository) acquire() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state == model.CircuitBreakerOpen {
+		wait := r.options.OpenTimeout - time.Since(r.openedAt)
+		if wait > 0 {
+			return app_error.WithRetryAfter(fmt.Errorf("%w: %s", app_error.ErrUnavailable, "car info provider is failing"), wait)
+		}
+		r.state = model.CircuitBreakerHalfOpen
+		r.probes, r.successes = 0, 0
+	}
+	if r.state == model.CircuitBreakerHalfOpen {
+		if r.probes >= r.options.Probes {
+			return app_error.WithRetryAfter(fmt.Errorf("%w: %s", app_error.ErrUnavailable, "car info provider is being probed"), time.Second)
+		}
+		r.probes++
+	}
+
+	return nil
+}
+
+// release records the outcome of a call and returns the new state when it
+// changed.
+func (r *Repository) release(failed bool) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch r.state {
+	case model.CircuitBreakerHalfOpen:
+		if failed {
+			r.open()
+			return r.state
+		}
+		r.successes++
+		if r.successes >= r.options.Probes {
+			r.state = model.CircuitBreakerClosed
+			r.failures = 0
+			return r.state
+		}
+	case model.CircuitBreakerClosed:
+		if !failed {
+			r.failures = 0
+			return ""
+		}
+		r.failures++
+		if r.failures >= r.options.Failures {
+			r.open()
+			return r.state
+		}
+	}
+
+	return ""
+}
+
+// cancel gives back the probe of a call abandoned by its caller, which
+// tells nothing about the provider.
+func (r *Repository) cancel() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state == model.CircuitBreakerHalfOpen && r.probes > 0 {
+		r.probes--
+	}
+}
+
+func (r *Repository) open() {
+	r.state = model.CircuitBreakerOpen
+	r.openedAt = time.Now()
+}
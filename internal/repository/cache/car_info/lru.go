@@ -0,0 +1,79 @@
+package car_info
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+)
+
+// LRU keeps the car info cache in memory, at most size lookups, evicting
+// the least recently used one first. Expired lookups are dropped when read.
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:    max(size, 1),
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached lookup of qry.RegNum unless it expired.
+func (c *LRU) Get(_ context.Context, qry *query.CarInfoCacheGet) (*model.CarInfoCache, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[qry.RegNum]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s - %s", app_error.ErrNotFound, "no cached car info for regNum", qry.RegNum)
+	}
+	cached := element.Value.(*model.CarInfoCache)
+	if !time.Now().Before(cached.ExpiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, qry.RegNum)
+		return nil, fmt.Errorf("%w: %s - %s", app_error.ErrNotFound, "no cached car info for regNum", qry.RegNum)
+	}
+	c.order.MoveToFront(element)
+	cache := *cached
+	cache.CarInfo = copyOf(cached.CarInfo)
+
+	return &cache, nil
+}
+
+// Set caches the lookup of qry.RegNum, replacing the one cached before.
+func (c *LRU) Set(_ context.Context, qry *query.CarInfoCacheSet) error {
+	cached := &model.CarInfoCache{
+		RegNum:    qry.RegNum,
+		CarInfo:   copyOf(qry.CarInfo),
+		NotFound:  qry.NotFound,
+		ExpiresAt: qry.ExpiresAt,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[cached.RegNum]; ok {
+		element.Value = cached
+		c.order.MoveToFront(element)
+		return nil
+	}
+	c.entries[cached.RegNum] = c.order.PushFront(cached)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*model.CarInfoCache).RegNum)
+	}
+
+	return nil
+}
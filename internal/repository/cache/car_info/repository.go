@@ -0,0 +1,145 @@
+package car_info
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"time"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+	"golang.org/x/sync/singleflight"
+)
+
+type carInfoRepository interface {
+	GetCarInfo(ctx context.Context, qry *query.CarInfo) (*model.CarInfo, error)
+}
+
+// carInfoStore keeps the cached lookups, in memory with LRU or in Postgres.
+type carInfoStore interface {
+	Get(ctx context.Context, qry *query.CarInfoCacheGet) (*model.CarInfoCache, error)
+	Set(ctx context.Context, qry *query.CarInfoCacheSet) error
+}
+
+// Options tune the cache: it holds car infos for TTL each, and remembers
+// regNums the provider does not know for NotFoundTTL.
+type Options struct {
+	TTL         time.Duration
+	NotFoundTTL time.Duration
+}
+
+// Repository caches the car infos of another car info repository in a
+// store. Concurrent lookups of the same regNum share one upstream call.
+type Repository struct {
+	next    carInfoRepository
+	store   carInfoStore
+	options Options
+	group   singleflight.Group
+}
+
+func New(next carInfoRepository, store carInfoStore, options Options) *Repository {
+	return &Repository{
+		next:    next,
+		store:   store,
+		options: options,
+	}
+}
+
+func (r *Repository) GetCarInfo(ctx context.Context, qry *query.CarInfo) (*model.CarInfo, error) {
+	const op = "repository.cache.car_info.GetCarInfo"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	if !qry.Fresh {
+		cached, err := r.store.Get(ctx, &query.CarInfoCacheGet{RegNum: qry.RegNum})
+		switch {
+		case err == nil && cached.NotFound:
+			log.Debug("got cached unknown car")
+			return nil, notFound(qry.RegNum)
+		case err == nil:
+			log.Debug("got cached car info", slog.Any("carInfo", cached.CarInfo))
+			return cached.CarInfo, nil
+		case !errors.Is(err, app_error.ErrNotFound):
+			// a cache that fails to answer is only slower
+			log.Error("failed to get cached car info", slog.String("error", err.Error()))
+		}
+	}
+
+	key := qry.RegNum
+	if qry.Fresh {
+		key = "fresh:" + key
+	}
+	// the shared call outlives any single caller giving up on it
+	results := r.group.DoChan(key, func() (interface{}, error) {
+		return r.lookup(context.WithoutCancel(ctx), qry)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		if result.Shared {
+			log.Debug("shared car info lookup")
+		}
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return copyOf(result.Val.(*model.CarInfo)), nil
+	}
+}
+
+// lookup asks the upstream repository and caches the car info, or the
+// not found error for a shorter time. Other errors are not cached, and
+// failing to cache only costs a later lookup.
+func (r *Repository) lookup(ctx context.Context, qry *query.CarInfo) (*model.CarInfo, error) {
+	const op = "repository.cache.car_info.lookup"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	carInfo, err := r.next.GetCarInfo(ctx, qry)
+	qrySet := query.CarInfoCacheSet{RegNum: qry.RegNum, CarInfo: carInfo}
+	switch {
+	case err == nil:
+		qrySet.ExpiresAt = time.Now().Add(r.options.TTL)
+	case errors.Is(err, app_error.ErrNotFound):
+		qrySet.NotFound = true
+		qrySet.ExpiresAt = time.Now().Add(r.options.NotFoundTTL)
+	default:
+		return nil, err
+	}
+	if setErr := r.store.Set(ctx, &qrySet); setErr != nil {
+		log.Error("failed to cache car info", slog.String("error", setErr.Error()))
+	}
+
+	return carInfo, err
+}
+
+func notFound(regNum string) error {
+	return fmt.Errorf("%w: %s - %s", app_error.ErrNotFound, "failed to get car info by regNum", regNum)
+}
+
+// copyOf keeps callers from changing the cached car info.
+func copyOf(carInfo *model.CarInfo) *model.CarInfo {
+	if carInfo == nil {
+		return nil
+	}
+	carInfoCopy := *carInfo
+	if carInfo.Year != nil {
+		year := *carInfo.Year
+		carInfoCopy.Year = &year
+	}
+	if carInfo.Owner != nil {
+		owner := *carInfo.Owner
+		carInfoCopy.Owner = &owner
+	}
+	carInfoCopy.Sources = maps.Clone(carInfo.Sources)
+
+	return &carInfoCopy
+}
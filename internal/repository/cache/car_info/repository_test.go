@@ -0,0 +1,168 @@
+package car_info_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+	"effective_mobile_2/internal/repository/cache/car_info"
+)
+
+// upstream stands in for the cached repository, answering err when set,
+// and counts the lookups that reach it. A lookup waits for release when
+// it is set.
+type upstream struct {
+	err     error
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (u *upstream) GetCarInfo(_ context.Context, qry *query.CarInfo) (*model.CarInfo, error) {
+	u.calls.Add(1)
+	if u.release != nil {
+		<-u.release
+	}
+	if u.err != nil {
+		return nil, u.err
+	}
+
+	return &model.CarInfo{Mark: "Lada", Model: qry.RegNum}, nil
+}
+
+func get(t *testing.T, repository *car_info.Repository, qry query.CarInfo) (*model.CarInfo, error) {
+	t.Helper()
+
+	return repository.GetCarInfo(context.Background(), &qry)
+}
+
+func TestGetCarInfoCaches(t *testing.T) {
+	app_log.Setup("error")
+
+	const ttl, notFoundTTL = 200 * time.Millisecond, 50 * time.Millisecond
+	errNotFound := app_error.ErrNotFound
+	errFailed := errors.New("provider failed")
+
+	tests := []struct {
+		name string
+		// err is what the upstream answers
+		err error
+		// wait is how long to wait between the two lookups
+		wait time.Duration
+		// fresh asks for the second lookup to skip the cache
+		fresh   bool
+		wantErr error
+		// wantCalls counts the lookups reaching the upstream
+		wantCalls int32
+	}{
+		{name: "car info cached", wantCalls: 1},
+		{name: "car info expired after ttl", wait: ttl + 50*time.Millisecond, wantCalls: 2},
+		{name: "fresh skips the cache", fresh: true, wantCalls: 2},
+		{name: "not found cached", err: errNotFound, wantErr: app_error.ErrNotFound, wantCalls: 1},
+		{name: "not found expired after the shorter ttl", err: errNotFound, wait: notFoundTTL + 50*time.Millisecond, wantErr: app_error.ErrNotFound, wantCalls: 2},
+		{name: "failure not cached", err: errFailed, wantErr: errFailed, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			next := &upstream{err: tt.err}
+			repository := car_info.New(next, car_info.NewLRU(10), car_info.Options{TTL: ttl, NotFoundTTL: notFoundTTL})
+
+			for i, qry := range []query.CarInfo{{RegNum: "X123XX150"}, {RegNum: "X123XX150", Fresh: tt.fresh}} {
+				if i > 0 {
+					time.Sleep(tt.wait)
+				}
+				carInfo, err := get(t, repository, qry)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("lookup %d: got error %v, want %v", i, err, tt.wantErr)
+				}
+				if tt.wantErr == nil && carInfo.Model != qry.RegNum {
+					t.Fatalf("lookup %d: got car info %+v", i, carInfo)
+				}
+			}
+			if calls := next.calls.Load(); calls != tt.wantCalls {
+				t.Errorf("got %d upstream calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestGetCarInfoEvictsLeastRecentlyUsed(t *testing.T) {
+	app_log.Setup("error")
+
+	next := &upstream{}
+	repository := car_info.New(next, car_info.NewLRU(2), car_info.Options{TTL: time.Hour, NotFoundTTL: time.Hour})
+
+	// A is used again after B, so caching C evicts B
+	for _, regNum := range []string{"A", "B", "A", "C"} {
+		if _, err := get(t, repository, query.CarInfo{RegNum: regNum}); err != nil {
+			t.Fatalf("%s: %v", regNum, err)
+		}
+	}
+	if calls := next.calls.Load(); calls != 3 {
+		t.Fatalf("got %d upstream calls, want 3", calls)
+	}
+
+	tests := []struct {
+		regNum    string
+		wantCalls int32
+	}{
+		{regNum: "A", wantCalls: 3},
+		{regNum: "C", wantCalls: 3},
+		{regNum: "B", wantCalls: 4},
+	}
+	for _, tt := range tests {
+		if _, err := get(t, repository, query.CarInfo{RegNum: tt.regNum}); err != nil {
+			t.Fatalf("%s: %v", tt.regNum, err)
+		}
+		if calls := next.calls.Load(); calls != tt.wantCalls {
+			t.Errorf("%s: got %d upstream calls, want %d", tt.regNum, calls, tt.wantCalls)
+		}
+	}
+}
+
+func TestGetCarInfoSharesConcurrentLookups(t *testing.T) {
+	app_log.Setup("error")
+
+	const lookups = 10
+	next := &upstream{release: make(chan struct{})}
+	repository := car_info.New(next, car_info.NewLRU(10), car_info.Options{TTL: time.Hour, NotFoundTTL: time.Hour})
+
+	var wg sync.WaitGroup
+	carInfos := make([]*model.CarInfo, lookups)
+	errs := make([]error, lookups)
+	for i := 0; i < lookups; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			carInfos[i], errs[i] = get(t, repository, query.CarInfo{RegNum: "X123XX150"})
+		}(i)
+	}
+	// let the lookups pile up on the first one before it answers
+	for next.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+
+	if calls := next.calls.Load(); calls != 1 {
+		t.Errorf("got %d upstream calls, want 1", calls)
+	}
+	for i := range carInfos {
+		if errs[i] != nil {
+			t.Fatalf("lookup %d: %v", i, errs[i])
+		}
+		if i > 0 && carInfos[i] == carInfos[0] {
+			t.Errorf("lookup %d shares its car info with lookup 0", i)
+		}
+	}
+}
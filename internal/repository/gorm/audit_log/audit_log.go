@@ -0,0 +1,45 @@
+package audit_log
+
+import (
+	"encoding/json"
+	"time"
+
+	"effective_mobile_2/internal/dto/model"
+)
+
+type AuditLog struct {
+	ID         uint      `gorm:"primary_key"`
+	EntityType string    `gorm:"type:varchar(50);not null;index:idx_audit_log_entity"`
+	EntityID   uint      `gorm:"not null;index:idx_audit_log_entity"`
+	Action     string    `gorm:"type:varchar(50);not null"`
+	Before     *string   `gorm:"type:jsonb"`
+	After      *string   `gorm:"type:jsonb"`
+	RequestID  string    `gorm:"type:varchar(100);index"`
+	Actor      string    `gorm:"type:varchar(100);index"`
+	CreatedAt  time.Time `gorm:"not null;index"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
+
+func ToModel(entity AuditLog) model.AuditLog {
+	auditLog := model.AuditLog{
+		ID:         entity.ID,
+		EntityType: entity.EntityType,
+		EntityID:   entity.EntityID,
+		Action:     entity.Action,
+		RequestID:  entity.RequestID,
+		Actor:      entity.Actor,
+		CreatedAt:  entity.CreatedAt,
+	}
+
+	if entity.Before != nil {
+		_ = json.Unmarshal([]byte(*entity.Before), &auditLog.Before)
+	}
+	if entity.After != nil {
+		_ = json.Unmarshal([]byte(*entity.After), &auditLog.After)
+	}
+
+	return auditLog
+}
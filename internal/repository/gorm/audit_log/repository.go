@@ -0,0 +1,186 @@
+package audit_log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+	"effective_mobile_2/internal/repository/gorm/transaction"
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) conn(ctx context.Context) *gorm.DB {
+	return transaction.Conn(ctx, r.db)
+}
+
+// Create records the fields qry.Before and qry.After differ in, within the
+// transaction of ctx when there is one.
+func (r *Repository) Create(ctx context.Context, qry *query.AuditLogCreate) (*model.AuditLog, error) {
+	const op = "repository.gorm.audit_log.Create"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("creating audit log")
+
+	before, after, err := diff(qry.Before, qry.After)
+	if err != nil {
+		log.Error("failed to diff", slog.String("error", err.Error()))
+		return nil, err
+	}
+	entity := AuditLog{
+		EntityType: qry.EntityType,
+		EntityID:   qry.EntityID,
+		Action:     qry.Action,
+		Before:     before,
+		After:      after,
+		RequestID:  qry.RequestID,
+		Actor:      qry.Actor,
+	}
+	result := r.conn(ctx).Create(&entity)
+	if result.Error != nil {
+		log.Error("failed to create audit log", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	auditLog := ToModel(entity)
+
+	log.Debug("created audit log", slog.Any("auditLog", auditLog))
+
+	return &auditLog, nil
+}
+
+func (r *Repository) List(ctx context.Context, qry *query.AuditLogList) (*[]model.AuditLog, int64, error) {
+	const op = "repository.gorm.audit_log.List"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("searching audit logs")
+
+	builder := r.conn(ctx).Model(&AuditLog{})
+	if qry.EntityType != nil {
+		builder = builder.Where("entity_type = ?", *qry.EntityType)
+	}
+	if qry.EntityID != nil {
+		builder = builder.Where("entity_id = ?", *qry.EntityID)
+	}
+	if qry.Action != nil {
+		builder = builder.Where("action = ?", *qry.Action)
+	}
+	if qry.Actor != nil {
+		builder = builder.Where("actor = ?", *qry.Actor)
+	}
+	if qry.RequestID != nil {
+		builder = builder.Where("request_id = ?", *qry.RequestID)
+	}
+	if qry.From != nil {
+		builder = builder.Where("created_at >= ?", *qry.From)
+	}
+	if qry.To != nil {
+		builder = builder.Where("created_at < ?", *qry.To)
+	}
+	builder = builder.Session(&gorm.Session{})
+
+	var total int64
+	result := builder.Count(&total)
+	if result.Error != nil {
+		log.Error("failed to count audit logs", slog.String("error", result.Error.Error()))
+		return nil, 0, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+
+	var entities []AuditLog
+	result = builder.
+		Order("id desc").
+		Limit(qry.Count).
+		Offset((qry.Page - 1) * qry.Count).
+		Find(&entities)
+	if result.Error != nil {
+		log.Error("failed to search audit logs", slog.String("error", result.Error.Error()))
+		return nil, 0, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	auditLogs := make([]model.AuditLog, len(entities))
+	for i, entity := range entities {
+		auditLogs[i] = ToModel(entity)
+	}
+
+	log.Debug("searched audit logs", slog.Any("auditLogs", auditLogs), slog.Int64("total", total))
+
+	return &auditLogs, total, nil
+}
+
+// diff returns the JSON of the fields before and after differ in. A side
+// is nil when the entity did not exist on it, and then is kept whole.
+func diff(before, after any) (*string, *string, error) {
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if beforeFields != nil && afterFields != nil {
+		for field, value := range beforeFields {
+			if otherValue, ok := afterFields[field]; ok && reflect.DeepEqual(value, otherValue) {
+				delete(beforeFields, field)
+				delete(afterFields, field)
+			}
+		}
+	}
+
+	beforeJSON, err := marshal(beforeFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterJSON, err := marshal(afterFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return beforeJSON, afterJSON, nil
+}
+
+func fields(value any) (map[string]any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+func marshal(fields map[string]any) (*string, error) {
+	if fields == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	value := string(data)
+
+	return &value, nil
+}
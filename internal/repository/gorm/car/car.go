@@ -1,18 +1,27 @@
 package car
 
 import (
+	"encoding/json"
+	"time"
+
 	"effective_mobile_2/internal/dto/model"
 	"effective_mobile_2/internal/repository/gorm/people"
+	"gorm.io/gorm"
 )
 
 type Car struct {
-	ID      uint   `gorm:"primary_key"`
-	RegNum  string `gorm:"unique;not null"`
-	Mark    string `gorm:"type:varchar(100)"`
-	Model   string `gorm:"type:varchar(100)"`
-	Year    int
-	Owner   people.People `gorm:"foreignKey:OwnerID"`
-	OwnerID uint
+	ID        uint   `gorm:"primary_key"`
+	RegNum    string `gorm:"not null;uniqueIndex:idx_cars_reg_num,where:deleted_at IS NULL"`
+	Mark      string `gorm:"type:varchar(100)"`
+	Model     string `gorm:"type:varchar(100)"`
+	Year      int
+	Sources   *string       `gorm:"type:jsonb"`
+	Owner     people.People `gorm:"foreignKey:OwnerID"`
+	OwnerID   uint
+	Version   uint           `gorm:"not null;default:1"`
+	CreatedAt time.Time      `gorm:"index"`
+	UpdatedAt time.Time      `gorm:"index"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
 func ToModel(entity Car) model.Car {
@@ -20,14 +29,28 @@ func ToModel(entity Car) model.Car {
 	if entity.Year != 0 {
 		carInfo.Year = &entity.Year
 	}
+	if entity.Sources != nil {
+		_ = json.Unmarshal([]byte(*entity.Sources), &carInfo.Sources)
+	}
 
 	car := model.Car{
 		ID:      entity.ID,
 		RegNum:  entity.RegNum,
 		OwnerID: entity.OwnerID,
+		Version: entity.Version,
 		CarInfo: carInfo,
 	}
 
+	if !entity.CreatedAt.IsZero() {
+		car.CreatedAt = &entity.CreatedAt
+	}
+	if !entity.UpdatedAt.IsZero() {
+		car.UpdatedAt = &entity.UpdatedAt
+	}
+	if entity.DeletedAt.Valid {
+		car.DeletedAt = &entity.DeletedAt.Time
+	}
+
 	if entity.Owner.ID != 0 {
 		owner := people.ToModel(entity.Owner)
 		car.Owner = &owner
@@ -35,3 +58,16 @@ func ToModel(entity Car) model.Car {
 
 	return car
 }
+
+func toSources(sources map[string]string) *string {
+	if len(sources) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(sources)
+	if err != nil {
+		return nil
+	}
+	value := string(data)
+
+	return &value
+}
@@ -1,17 +1,25 @@
 package car
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"effective_mobile_2/internal/app_error"
 	"effective_mobile_2/internal/app_log"
 	"effective_mobile_2/internal/dto/model"
 	"effective_mobile_2/internal/dto/query"
+	"effective_mobile_2/internal/repository/gorm/transaction"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// uniqueViolation is the Postgres error code of a unique constraint violation.
+const uniqueViolation = "23505"
+
 type Repository struct {
 	db *gorm.DB
 }
@@ -20,7 +28,11 @@ func New(db *gorm.DB) *Repository {
 	return &Repository{db: db}
 }
 
-func (r *Repository) List(qry *query.CarList) (*[]model.Car, error) {
+func (r *Repository) conn(ctx context.Context) *gorm.DB {
+	return transaction.Conn(ctx, r.db)
+}
+
+func (r *Repository) List(ctx context.Context, qry *query.CarList) (*[]model.Car, int64, error) {
 	const op = "repository.gorm.car.List"
 	log := app_log.Logger().With(
 		slog.String("op", op),
@@ -29,30 +41,201 @@ func (r *Repository) List(qry *query.CarList) (*[]model.Car, error) {
 
 	log.Info("searching cars")
 
-	builder := r.db.Model(&Car{})
+	order, err := orderClause(qry)
+	if err != nil {
+		log.Error("failed to build order", slog.String("error", err.Error()))
+		return nil, 0, err
+	}
+	builder := r.filter(ctx, qry)
+
+	var total int64
+	result := builder.Count(&total)
+	if result.Error != nil {
+		log.Error("failed to count cars", slog.String("error", result.Error.Error()))
+		return nil, 0, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+
+	var entities []Car
+	result = builder.
+		Preload("Owner").
+		Clauses(order).
+		Limit(qry.Count).
+		Offset((qry.Page - 1) * qry.Count).
+		Find(&entities)
+	if result.Error != nil {
+		log.Error("failed to search cars", slog.String("error", result.Error.Error()))
+		return nil, 0, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	cars := make([]model.Car, len(entities))
+	for i, entity := range entities {
+		cars[i] = ToModel(entity)
+	}
+
+	log.Debug("searched cars", slog.Any("cars", cars), slog.Int64("total", total))
+
+	return &cars, total, nil
+}
+
+// Scroll is the keyset counterpart of List: it reads the page that follows
+// qry.Cursor and returns the cursor of the next page, or nil on the last one.
+func (r *Repository) Scroll(ctx context.Context, qry *query.CarList) (*[]model.Car, *query.CarCursor, error) {
+	const op = "repository.gorm.car.Scroll"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("scrolling cars")
+
+	order, err := orderClause(qry)
+	if err != nil {
+		log.Error("failed to build order", slog.String("error", err.Error()))
+		return nil, nil, err
+	}
+	builder := r.filter(ctx, qry)
+	if qry.Cursor != nil {
+		condition, vars, err := keysetCondition(qry.Sort, qry.Cursor)
+		if err != nil {
+			log.Error("failed to build keyset condition", slog.String("error", err.Error()))
+			return nil, nil, err
+		}
+		builder = builder.Where(condition, vars...)
+	}
+
+	var entities []Car
+	result := builder.
+		Preload("Owner").
+		Clauses(order).
+		Limit(qry.Count + 1).
+		Find(&entities)
+	if result.Error != nil {
+		log.Error("failed to scroll cars", slog.String("error", result.Error.Error()))
+		return nil, nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+
+	var next *query.CarCursor
+	if len(entities) > qry.Count {
+		entities = entities[:qry.Count]
+		next = cursorOf(qry.Sort, entities[len(entities)-1])
+	}
+	cars := make([]model.Car, len(entities))
+	for i, entity := range entities {
+		cars[i] = ToModel(entity)
+	}
+
+	log.Debug("scrolled cars", slog.Any("cars", cars), slog.Any("next", next))
+
+	return &cars, next, nil
+}
+
+func (r *Repository) filter(ctx context.Context, qry *query.CarList) *gorm.DB {
+	builder := r.conn(ctx).Model(&Car{})
+	if qry.OnlyDeleted {
+		builder = builder.Unscoped().Where("cars.deleted_at IS NOT NULL")
+	} else if qry.IncludeDeleted {
+		builder = builder.Unscoped()
+	}
+	if filtersByOwner(qry) || sortsByOwner(qry.Sort) {
+		builder = builder.Joins("JOIN peoples ON peoples.id = cars.owner_id")
+	}
 	if qry.RegNum != nil {
-		builder.Where("reg_num = ?", *qry.RegNum)
+		builder = builder.Where("cars.reg_num = ?", *qry.RegNum)
 	}
-	if qry.Mark != nil {
-		builder = builder.Where("mark LIKE ?", "%"+*qry.Mark+"%")
+	if qry.RegNumPrefix != nil {
+		builder = builder.Where(like("cars.reg_num"), escapeLike(*qry.RegNumPrefix)+"%")
+	}
+	if len(qry.Marks) > 0 {
+		condition, vars := matchAny("cars.mark", qry.MarkMatch, qry.Marks)
+		builder = builder.Where(condition, vars...)
 	}
 	if qry.Model != nil {
-		builder = builder.Where("model LIKE ?", "%"+*qry.Model+"%")
+		builder = builder.Where(like("cars.model"), "%"+escapeLike(*qry.Model)+"%")
 	}
 	if qry.Year != nil {
-		builder = builder.Where("year = ?", qry.Year)
+		builder = builder.Where("cars.year = ?", qry.Year)
+	}
+	if qry.YearFrom != nil {
+		builder = builder.Where("cars.year >= ?", *qry.YearFrom)
+	}
+	if qry.YearTo != nil {
+		builder = builder.Where("cars.year <= ?", *qry.YearTo)
+	}
+	if qry.OwnedAt != nil {
+		condition, vars := ownedAtCondition(*qry.OwnedAt, qry.OwnerID)
+		builder = builder.Where(condition, vars...)
+	} else if qry.OwnerID != nil {
+		builder = builder.Where("cars.owner_id = ?", *qry.OwnerID)
 	}
 	if qry.OwnerName != nil {
-		builder.Joins("JOIN peoples ON peoples.id = cars.owner_id").Where("peoples.name LIKE ?", "%"+*qry.OwnerName+"%")
+		builder = builder.Where(match("peoples.name", qry.OwnerMatch, *qry.OwnerName))
 	}
 	if qry.OwnerSurname != nil {
-		builder.Joins("JOIN peoples ON peoples.id = cars.owner_id").Where("peoples.surname = ?", "%"+*qry.OwnerSurname+"%")
+		builder = builder.Where(match("peoples.surname", qry.OwnerMatch, *qry.OwnerSurname))
+	}
+	if qry.OwnerPatronymic != nil {
+		builder = builder.Where(match("peoples.patronymic", qry.OwnerMatch, *qry.OwnerPatronymic))
+	}
+	if qry.HasPatronymic != nil {
+		if *qry.HasPatronymic {
+			builder = builder.Where("COALESCE(peoples.patronymic, '') <> ''")
+		} else {
+			builder = builder.Where("COALESCE(peoples.patronymic, '') = ''")
+		}
+	}
+	if qry.CreatedFrom != nil {
+		builder = builder.Where("cars.created_at >= ?", *qry.CreatedFrom)
 	}
-	builder.Preload("Owner")
-	builder = builder.Order(fmt.Sprintf("id %s", qry.Order))
-	builder = builder.Limit(qry.Count).Offset((qry.Page - 1) * qry.Count)
+	if qry.CreatedTo != nil {
+		builder = builder.Where("cars.created_at < ?", *qry.CreatedTo)
+	}
+	if qry.UpdatedSince != nil {
+		// a deletion leaves updated_at as is but counts as an update
+		builder = builder.Where("(cars.updated_at >= ? OR cars.deleted_at >= ?)", *qry.UpdatedSince, *qry.UpdatedSince)
+	}
+	if qry.Search != nil {
+		builder = builder.Where(searchCondition, *qry.Search, *qry.Search)
+	}
+
+	return builder.Session(&gorm.Session{})
+}
+
+func (r *Repository) Get(ctx context.Context, qry *query.CarGet) (*model.Car, error) {
+	const op = "repository.gorm.car.Get"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("searching car")
+
+	var entity Car
+	result := r.conn(ctx).Preload("Owner").First(&entity, qry.ID)
+	if result.Error != nil {
+		log.Error("failed to search", slog.String("error", result.Error.Error()))
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s - %d", app_error.ErrNotFound, "failed to search by id", qry.ID)
+		}
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	car := ToModel(entity)
+
+	log.Debug("searched car", slog.Any("car", car))
+
+	return &car, nil
+}
+
+// ListByRegNums returns the cars stored under any of qry.RegNums.
+func (r *Repository) ListByRegNums(ctx context.Context, qry *query.CarListByRegNums) (*[]model.Car, error) {
+	const op = "repository.gorm.car.ListByRegNums"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("searching cars")
+
 	var entities []Car
-	result := builder.Find(&entities)
+	result := r.conn(ctx).Preload("Owner").Where("reg_num IN ?", qry.RegNums).Find(&entities)
 	if result.Error != nil {
 		log.Error("failed to search cars", slog.String("error", result.Error.Error()))
 		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
@@ -67,7 +250,7 @@ func (r *Repository) List(qry *query.CarList) (*[]model.Car, error) {
 	return &cars, nil
 }
 
-func (r *Repository) Create(qry *query.CarCreate) (*model.Car, error) {
+func (r *Repository) Create(ctx context.Context, qry *query.CarCreate) (*model.Car, error) {
 	const op = "repository.gorm.car.Create"
 	log := app_log.Logger().With(
 		slog.String("op", op),
@@ -80,19 +263,24 @@ func (r *Repository) Create(qry *query.CarCreate) (*model.Car, error) {
 		RegNum:  qry.RegNum,
 		Mark:    qry.Mark,
 		Model:   qry.Model,
+		Sources: toSources(qry.Sources),
 		OwnerID: qry.OwnerID,
 	}
 	if qry.Year != nil {
 		entity.Year = *qry.Year
 	}
-	result := r.db.Create(&entity)
+	result := r.conn(ctx).Create(&entity)
 	if result.Error != nil {
 		log.Error("failed to create car", slog.String("error", result.Error.Error()))
+		var pgErr *pgconn.PgError
+		if errors.As(result.Error, &pgErr) && pgErr.Code == uniqueViolation {
+			return nil, fmt.Errorf("%w: %s - %s", app_error.ErrConflict, "reg number is taken", qry.RegNum)
+		}
 		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
 	}
 
 	var fullEntity Car
-	if err := r.db.Preload("Owner").First(&fullEntity, entity.ID).Error; err != nil {
+	if err := r.conn(ctx).Preload("Owner").First(&fullEntity, entity.ID).Error; err != nil {
 		log.Error("failed to load car with owner", slog.String("error", err.Error()))
 		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, err)
 	}
@@ -103,7 +291,7 @@ func (r *Repository) Create(qry *query.CarCreate) (*model.Car, error) {
 	return &car, nil
 }
 
-func (r *Repository) Update(qry *query.CarUpdate) (*model.Car, error) {
+func (r *Repository) Update(ctx context.Context, qry *query.CarUpdate) (*model.Car, error) {
 	const op = "repository.gorm.car.Update"
 	log := app_log.Logger().With(
 		slog.String("op", op),
@@ -113,7 +301,7 @@ func (r *Repository) Update(qry *query.CarUpdate) (*model.Car, error) {
 	log.Info("searching car")
 
 	var entity Car
-	result := r.db.Preload("Owner").First(&entity, qry.ID)
+	result := r.conn(ctx).First(&entity, qry.ID)
 	if result.Error != nil {
 		log.Error("failed to search", slog.String("error", result.Error.Error()))
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -121,6 +309,10 @@ func (r *Repository) Update(qry *query.CarUpdate) (*model.Car, error) {
 		}
 		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
 	}
+	if qry.Version != nil && *qry.Version != entity.Version {
+		log.Error("failed to match version", slog.Uint64("version", uint64(entity.Version)))
+		return nil, fmt.Errorf("%w: %s - %d", app_error.ErrPreconditionFailed, "car version is", entity.Version)
+	}
 
 	log.Debug("searched car", slog.Any("entity", entity))
 	log.Info("updating car", slog.Any("entity", entity))
@@ -137,11 +329,41 @@ func (r *Repository) Update(qry *query.CarUpdate) (*model.Car, error) {
 	if qry.Year != nil {
 		entity.Year = *qry.Year
 	}
-	result = r.db.Save(&entity)
+	if qry.Sources != nil {
+		entity.Sources = toSources(qry.Sources)
+	}
+	if qry.OwnerID != nil {
+		entity.OwnerID = *qry.OwnerID
+	}
+	// the version guards against changes made since the car was read
+	result = r.conn(ctx).
+		Model(&Car{}).
+		Where("id = ? AND version = ?", entity.ID, entity.Version).
+		Updates(map[string]any{
+			"reg_num":  entity.RegNum,
+			"mark":     entity.Mark,
+			"model":    entity.Model,
+			"year":     entity.Year,
+			"sources":  entity.Sources,
+			"owner_id": entity.OwnerID,
+			"version":  gorm.Expr("version + 1"),
+		})
 	if result.Error != nil {
 		log.Error("failed to update car", slog.String("error", result.Error.Error()))
+		var pgErr *pgconn.PgError
+		if errors.As(result.Error, &pgErr) && pgErr.Code == uniqueViolation {
+			return nil, fmt.Errorf("%w: %s - %s", app_error.ErrConflict, "reg number is taken", entity.RegNum)
+		}
 		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
 	}
+	if result.RowsAffected == 0 {
+		log.Error("failed to update car changed concurrently")
+		return nil, fmt.Errorf("%w: %s - %d", app_error.ErrPreconditionFailed, "car changed concurrently", entity.ID)
+	}
+	if err := r.conn(ctx).Preload("Owner").First(&entity, entity.ID).Error; err != nil {
+		log.Error("failed to load car with owner", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, err)
+	}
 	car := ToModel(entity)
 
 	log.Debug("updated car", slog.Any("car", car))
@@ -149,7 +371,71 @@ func (r *Repository) Update(qry *query.CarUpdate) (*model.Car, error) {
 	return &car, nil
 }
 
-func (r *Repository) Delete(qry *query.CarDelete) error {
+// ListByOwnerIDs locks and returns the cars of the owners, deleted ones
+// included, for a change of all of them within the transaction of ctx.
+func (r *Repository) ListByOwnerIDs(ctx context.Context, qry *query.CarListByOwnerIDs) (*[]model.Car, error) {
+	const op = "repository.gorm.car.ListByOwnerIDs"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("searching cars")
+
+	var entities []Car
+	result := r.conn(ctx).
+		Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("owner_id IN ?", qry.OwnerIDs).
+		Order("id").
+		Find(&entities)
+	if result.Error != nil {
+		log.Error("failed to search cars", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	cars := make([]model.Car, len(entities))
+	for i, entity := range entities {
+		cars[i] = ToModel(entity)
+	}
+
+	log.Debug("searched cars", slog.Any("cars", cars))
+
+	return &cars, nil
+}
+
+// ReassignOwner moves every car of qry.FromOwnerIDs to qry.ToOwnerID and
+// returns the moved cars as they became, deleted ones included.
+func (r *Repository) ReassignOwner(ctx context.Context, qry *query.CarReassignOwner) (*[]model.Car, error) {
+	const op = "repository.gorm.car.ReassignOwner"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("reassigning cars")
+
+	var entities []Car
+	result := r.conn(ctx).
+		Unscoped().
+		Model(&entities).
+		Clauses(clause.Returning{}).
+		Where("owner_id IN ?", qry.FromOwnerIDs).
+		Updates(map[string]any{"owner_id": qry.ToOwnerID, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		log.Error("failed to reassign cars", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	cars := make([]model.Car, len(entities))
+	for i, entity := range entities {
+		cars[i] = ToModel(entity)
+	}
+
+	log.Debug("reassigned cars", slog.Int64("count", result.RowsAffected))
+
+	return &cars, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, qry *query.CarDelete) error {
 	const op = "repository.gorm.car.Delete"
 	log := app_log.Logger().With(
 		slog.String("op", op),
@@ -158,7 +444,7 @@ func (r *Repository) Delete(qry *query.CarDelete) error {
 
 	log.Info("deleting car")
 
-	result := r.db.Delete(&Car{}, qry.ID)
+	result := r.conn(ctx).Delete(&Car{}, qry.ID)
 	if result.RowsAffected == 0 {
 		log.Error("failed to delete car")
 		return fmt.Errorf("%w: %s - %d", app_error.ErrNotFound, "failed to delete by id", qry.ID)
@@ -172,3 +458,110 @@ func (r *Repository) Delete(qry *query.CarDelete) error {
 
 	return nil
 }
+
+// Restore brings back a deleted car unless its registration number has been
+// taken since.
+func (r *Repository) Restore(ctx context.Context, qry *query.CarRestore) (*model.Car, error) {
+	const op = "repository.gorm.car.Restore"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("searching deleted car")
+
+	var entity Car
+	result := r.conn(ctx).Unscoped().Where("deleted_at IS NOT NULL").First(&entity, qry.ID)
+	if result.Error != nil {
+		log.Error("failed to search", slog.String("error", result.Error.Error()))
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s - %d", app_error.ErrNotFound, "failed to search deleted by id", qry.ID)
+		}
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+
+	log.Debug("searched deleted car", slog.Any("entity", entity))
+	log.Info("restoring car", slog.Any("entity", entity))
+
+	var count int64
+	result = r.conn(ctx).Model(&Car{}).Where("reg_num = ?", entity.RegNum).Count(&count)
+	if result.Error != nil {
+		log.Error("failed to search reg number", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	if count > 0 {
+		log.Error("failed to restore car with taken reg number")
+		return nil, fmt.Errorf("%w: %s - %s", app_error.ErrConflict, "reg number is taken", entity.RegNum)
+	}
+	result = r.conn(ctx).
+		Unscoped().
+		Model(&entity).
+		Updates(map[string]any{"deleted_at": nil, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		log.Error("failed to restore car", slog.String("error", result.Error.Error()))
+		// the reg number may be taken between the count and the update
+		var pgErr *pgconn.PgError
+		if errors.As(result.Error, &pgErr) && pgErr.Code == uniqueViolation {
+			return nil, fmt.Errorf("%w: %s - %s", app_error.ErrConflict, "reg number is taken", entity.RegNum)
+		}
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+
+	return r.Get(ctx, &query.CarGet{ID: qry.ID})
+}
+
+// Purge removes the cars deleted before qry.DeletedBefore for good and
+// returns them as they were.
+func (r *Repository) Purge(ctx context.Context, qry *query.CarPurge) (*[]model.Car, error) {
+	const op = "repository.gorm.car.Purge"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("purging cars")
+
+	var entities []Car
+	result := r.conn(ctx).
+		Unscoped().
+		Clauses(clause.Returning{}).
+		Where("deleted_at < ?", qry.DeletedBefore).
+		Delete(&entities)
+	if result.Error != nil {
+		log.Error("failed to purge cars", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	cars := make([]model.Car, len(entities))
+	for i, entity := range entities {
+		cars[i] = ToModel(entity)
+	}
+
+	log.Debug("purged cars", slog.Int64("count", result.RowsAffected))
+
+	return &cars, nil
+}
+
+// filtersByOwner reports whether the filters need the owner joined. The join
+// is added once for all owner conditions, the sort and the search.
+func filtersByOwner(qry *query.CarList) bool {
+	return qry.OwnerName != nil ||
+		qry.OwnerSurname != nil ||
+		qry.OwnerPatronymic != nil ||
+		qry.HasPatronymic != nil ||
+		qry.Search != nil
+}
+
+// ownedAtCondition matches cars owned at the given time, by ownerID when
+// set. A period of unknown start covers everything before its end.
+func ownedAtCondition(at time.Time, ownerID *uint) (string, []any) {
+	condition := "EXISTS (SELECT 1 FROM car_ownerships WHERE car_ownerships.car_id = cars.id" +
+		" AND (car_ownerships.owned_from IS NULL OR car_ownerships.owned_from <= ?)" +
+		" AND (car_ownerships.owned_to IS NULL OR car_ownerships.owned_to > ?)"
+	vars := []any{at, at}
+	if ownerID != nil {
+		condition += " AND car_ownerships.owner_id = ?"
+		vars = append(vars, *ownerID)
+	}
+
+	return condition + ")", vars
+}
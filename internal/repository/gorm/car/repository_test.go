@@ -0,0 +1,221 @@
+package car_test
+
+import (
+	"context"
+	"testing"
+
+	"effective_mobile_2/internal/database/dbtest"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+	"effective_mobile_2/internal/repository/gorm/car"
+	"effective_mobile_2/internal/repository/gorm/people"
+	"gorm.io/gorm"
+)
+
+// regNumPrefix keeps the lists to the cars the tests create.
+const regNumPrefix = "TST"
+
+type seed struct {
+	regNum, mark, model string
+	name, surname       string
+	patronymic          *string
+}
+
+func create(t *testing.T, carRepository *car.Repository, peopleRepository *people.Repository, seeds []seed) {
+	t.Helper()
+
+	owners := map[string]uint{}
+	for _, s := range seeds {
+		ownerID, ok := owners[s.surname+" "+s.name]
+		if !ok {
+			owner, err := peopleRepository.Create(context.Background(), &query.PeopleCreate{Name: s.name, Surname: s.surname, Patronymic: s.patronymic})
+			if err != nil {
+				t.Fatalf("failed to create people: %v", err)
+			}
+			ownerID = owner.ID
+			owners[s.surname+" "+s.name] = ownerID
+		}
+		qry := query.CarCreate{RegNum: s.regNum, Mark: s.mark, Model: s.model, OwnerID: ownerID}
+		if _, err := carRepository.Create(context.Background(), &qry); err != nil {
+			t.Fatalf("failed to create car: %v", err)
+		}
+	}
+}
+
+func list(t *testing.T, carRepository *car.Repository, qry query.CarList) []string {
+	t.Helper()
+
+	prefix := regNumPrefix
+	qry.RegNumPrefix = &prefix
+	qry.Sort = []query.CarSort{{Field: query.CarSortRegNum}}
+	qry.Page, qry.Count = 1, 100
+	cars, total, err := carRepository.List(context.Background(), &qry)
+	if err != nil {
+		t.Fatalf("failed to list cars: %v", err)
+	}
+	if total != int64(len(*cars)) {
+		t.Errorf("total is %d for %d cars", total, len(*cars))
+	}
+
+	return regNums(*cars)
+}
+
+func regNums(cars []model.Car) []string {
+	result := make([]string, len(cars))
+	for i, c := range cars {
+		result[i] = c.RegNum
+	}
+
+	return result
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+var seeds = []seed{
+	{regNum: "TST001", mark: "Lada", model: "Vesta", name: "Ivan", surname: "Ivanov"},
+	{regNum: "TST002", mark: "Lada", model: "Granta", name: "Ivan", surname: "Ivanov"},
+	{regNum: "TST003", mark: "BMW", model: "X5", name: "Ivanka", surname: "Petrova"},
+	{regNum: "TST004", mark: "Škoda", model: "Octavia", name: "Savanna", surname: "Ёлкина"},
+}
+
+func TestListOwnerFiltersJoinOwnerOnce(t *testing.T) {
+	db := dbtest.Open(t)
+
+	dbtest.Rollback(t, db, func(tx *gorm.DB) {
+		carRepository, peopleRepository := car.New(tx), people.New(tx)
+		create(t, carRepository, peopleRepository, seeds)
+
+		name, surname := "Ivan", "Ivanov"
+		qry := query.CarList{
+			OwnerName:    &name,
+			OwnerSurname: &surname,
+			OwnerMatch:   query.MatchExact,
+			Sort:         []query.CarSort{{Field: query.CarSortOwnerSurname}},
+		}
+		prefix := regNumPrefix
+		qry.RegNumPrefix = &prefix
+		qry.Sort = append(qry.Sort, query.CarSort{Field: query.CarSortRegNum})
+		qry.Page, qry.Count = 1, 100
+		cars, total, err := carRepository.List(context.Background(), &qry)
+		if err != nil {
+			t.Fatalf("failed to list cars: %v", err)
+		}
+		if got := regNums(*cars); total != 2 || !equal(got, []string{"TST001", "TST002"}) {
+			t.Errorf("got %v of %d, want [TST001 TST002] of 2", got, total)
+		}
+	})
+}
+
+func TestListOwnerMatch(t *testing.T) {
+	db := dbtest.Open(t)
+
+	tests := []struct {
+		name  string
+		match string
+		value string
+		want  []string
+	}{
+		{"exact ignores case", query.MatchExact, "IVAN", []string{"TST001", "TST002"}},
+		{"exact ignores accents", query.MatchExact, "savánna", []string{"TST004"}},
+		{"prefix", query.MatchPrefix, "ivan", []string{"TST001", "TST002", "TST003"}},
+		{"contains", query.MatchContains, "van", []string{"TST001", "TST002", "TST003", "TST004"}},
+		{"contains escapes wildcards", query.MatchContains, "%", []string{}},
+	}
+
+	dbtest.Rollback(t, db, func(tx *gorm.DB) {
+		carRepository, peopleRepository := car.New(tx), people.New(tx)
+		create(t, carRepository, peopleRepository, seeds)
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.name, func(t *testing.T) {
+				value := tt.value
+				got := list(t, carRepository, query.CarList{OwnerName: &value, OwnerMatch: tt.match})
+				if !equal(got, tt.want) {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			})
+		}
+
+		t.Run("surname ignores accents", func(t *testing.T) {
+			surname := "елкина"
+			got := list(t, carRepository, query.CarList{OwnerSurname: &surname, OwnerMatch: query.MatchExact})
+			if !equal(got, []string{"TST004"}) {
+				t.Errorf("got %v, want [TST004]", got)
+			}
+		})
+	})
+}
+
+func TestListMarkMatch(t *testing.T) {
+	db := dbtest.Open(t)
+
+	tests := []struct {
+		name  string
+		match string
+		marks []string
+		want  []string
+	}{
+		{"one contains", query.MatchContains, []string{"BM"}, []string{"TST003"}},
+		{"one exact", query.MatchExact, []string{"lada"}, []string{"TST001", "TST002"}},
+		{"several exact", query.MatchExact, []string{"lada", "skoda"}, []string{"TST001", "TST002", "TST004"}},
+		{"exact is not partial", query.MatchExact, []string{"lad"}, []string{}},
+		{"several contains", query.MatchContains, []string{"ad", "mw"}, []string{"TST001", "TST002", "TST003"}},
+	}
+
+	dbtest.Rollback(t, db, func(tx *gorm.DB) {
+		carRepository, peopleRepository := car.New(tx), people.New(tx)
+		create(t, carRepository, peopleRepository, seeds)
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.name, func(t *testing.T) {
+				got := list(t, carRepository, query.CarList{Marks: tt.marks, MarkMatch: tt.match})
+				if !equal(got, tt.want) {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestListFuzzySearch(t *testing.T) {
+	db := dbtest.Open(t)
+
+	tests := []struct {
+		name   string
+		search string
+		want   []string
+	}{
+		{"typo in model", "vestaa", []string{"TST001"}},
+		{"owner surname", "petrova", []string{"TST003"}},
+		{"nothing similar", "toyota", []string{}},
+	}
+
+	dbtest.Rollback(t, db, func(tx *gorm.DB) {
+		carRepository, peopleRepository := car.New(tx), people.New(tx)
+		create(t, carRepository, peopleRepository, seeds)
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.name, func(t *testing.T) {
+				search := tt.search
+				got := list(t, carRepository, query.CarList{Search: &search})
+				if !equal(got, tt.want) {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			})
+		}
+	})
+}
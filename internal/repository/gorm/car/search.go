@@ -0,0 +1,69 @@
+package car
+
+import (
+	"strings"
+
+	"effective_mobile_2/internal/dto/query"
+)
+
+// Text matching is done on lowercased, unaccented values so that "bmw"
+// finds "BMW" and "ё" matches "е". The expressions below must stay in sync
+// with the trigram indexes created in database.Migrate, otherwise Postgres
+// falls back to sequential scans.
+const (
+	carSearchExpr   = "f_unaccent(lower(cars.reg_num || ' ' || cars.mark || ' ' || cars.model))"
+	ownerSearchExpr = "f_unaccent(lower(peoples.surname || ' ' || peoples.name || ' ' || COALESCE(peoples.patronymic, '')))"
+	searchCondition = "(f_unaccent(lower(?)) <% " + carSearchExpr + " OR f_unaccent(lower(?)) <% " + ownerSearchExpr + ")"
+	relevanceExpr   = "GREATEST(word_similarity(f_unaccent(lower(?)), " + carSearchExpr + "), word_similarity(f_unaccent(lower(?)), " + ownerSearchExpr + "))"
+)
+
+func normalize(expr string) string {
+	return "f_unaccent(lower(" + expr + "))"
+}
+
+func in(expr string, values []string) (string, []interface{}) {
+	placeholders := make([]string, len(values))
+	vars := make([]interface{}, len(values))
+	for i, value := range values {
+		placeholders[i] = normalize("?")
+		vars[i] = value
+	}
+
+	return normalize(expr) + " IN (" + strings.Join(placeholders, ", ") + ")", vars
+}
+
+func like(expr string) string {
+	return normalize(expr) + " LIKE " + normalize("?")
+}
+
+// match compares expr with value according to one of the query.Match* modes,
+// falling back to contains for an unknown mode.
+func match(expr string, mode string, value string) (string, interface{}) {
+	switch mode {
+	case query.MatchExact:
+		return normalize(expr) + " = " + normalize("?"), value
+	case query.MatchPrefix:
+		return like(expr), escapeLike(value) + "%"
+	default:
+		return like(expr), "%" + escapeLike(value) + "%"
+	}
+}
+
+// matchAny compares expr with each of values like match does and holds when
+// any of them matches, exact matching becomes a single IN.
+func matchAny(expr string, mode string, values []string) (string, []interface{}) {
+	if mode == query.MatchExact {
+		return in(expr, values)
+	}
+	conditions := make([]string, len(values))
+	vars := make([]interface{}, len(values))
+	for i, value := range values {
+		conditions[i], vars[i] = match(expr, mode, value)
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")", vars
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
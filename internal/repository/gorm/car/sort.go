@@ -0,0 +1,164 @@
+package car
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/dto/query"
+	"gorm.io/gorm/clause"
+)
+
+type sortColumn struct {
+	expr    string
+	owner   bool
+	numeric bool
+	search  bool
+	value   func(entity Car) string
+}
+
+var sortColumns = map[string]sortColumn{
+	query.CarSortID: {
+		expr:    "cars.id",
+		numeric: true,
+		value:   func(entity Car) string { return strconv.FormatUint(uint64(entity.ID), 10) },
+	},
+	query.CarSortRegNum: {
+		expr:  "cars.reg_num",
+		value: func(entity Car) string { return entity.RegNum },
+	},
+	query.CarSortMark: {
+		expr:  "cars.mark",
+		value: func(entity Car) string { return entity.Mark },
+	},
+	query.CarSortModel: {
+		expr:  "cars.model",
+		value: func(entity Car) string { return entity.Model },
+	},
+	query.CarSortYear: {
+		expr:    "cars.year",
+		numeric: true,
+		value:   func(entity Car) string { return strconv.Itoa(entity.Year) },
+	},
+	query.CarSortOwnerName: {
+		expr:  "peoples.name",
+		owner: true,
+		value: func(entity Car) string { return entity.Owner.Name },
+	},
+	query.CarSortOwnerSurname: {
+		expr:  "peoples.surname",
+		owner: true,
+		value: func(entity Car) string { return entity.Owner.Surname },
+	},
+	query.CarSortOwnerPatronymic: {
+		expr:  "COALESCE(peoples.patronymic, '')",
+		owner: true,
+		value: func(entity Car) string { return entity.Owner.Patronymic },
+	},
+	query.CarSortRelevance: {
+		expr:   relevanceExpr,
+		owner:  true,
+		search: true,
+	},
+}
+
+func sortsByOwner(sorts []query.CarSort) bool {
+	for _, sort := range sorts {
+		if sortColumns[sort.Field].owner {
+			return true
+		}
+	}
+
+	return false
+}
+
+func orderClause(qry *query.CarList) (clause.OrderBy, error) {
+	parts := make([]string, len(qry.Sort))
+	vars := make([]interface{}, 0)
+	for i, sort := range qry.Sort {
+		column, ok := sortColumns[sort.Field]
+		if !ok {
+			return clause.OrderBy{}, fmt.Errorf("%w: %s - %s", app_error.ErrInvalidArgument, "unknown sort field", sort.Field)
+		}
+		if column.search {
+			if qry.Search == nil {
+				return clause.OrderBy{}, fmt.Errorf("%w: %s", app_error.ErrInvalidArgument, "relevance sort requires search")
+			}
+			vars = append(vars, *qry.Search, *qry.Search)
+		}
+		parts[i] = column.expr + " " + direction(sort)
+	}
+
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:                strings.Join(parts, ", "),
+		Vars:               vars,
+		WithoutParentheses: true,
+	}}, nil
+}
+
+// keysetCondition builds the row comparison that selects everything after
+// the cursor for a mixed-direction ordering:
+// (a > ?) OR (a = ? AND b < ?) OR (a = ? AND b = ? AND c > ?) ...
+func keysetCondition(sorts []query.CarSort, cursor *query.CarCursor) (string, []interface{}, error) {
+	args := make([]interface{}, len(sorts))
+	for i, sort := range sorts {
+		if sortColumns[sort.Field].search {
+			return "", nil, fmt.Errorf("%w: %s", app_error.ErrInvalidArgument, "relevance sort does not support cursor")
+		}
+		arg, err := sortArg(sortColumns[sort.Field], cursor.Values[i])
+		if err != nil {
+			return "", nil, err
+		}
+		args[i] = arg
+	}
+
+	disjuncts := make([]string, len(sorts))
+	vars := make([]interface{}, 0, len(sorts)*(len(sorts)+1)/2)
+	for i, sort := range sorts {
+		conjuncts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			conjuncts = append(conjuncts, sortColumns[sorts[j].Field].expr+" = ?")
+			vars = append(vars, args[j])
+		}
+		operator := ">"
+		if sort.Desc {
+			operator = "<"
+		}
+		conjuncts = append(conjuncts, sortColumns[sort.Field].expr+" "+operator+" ?")
+		vars = append(vars, args[i])
+		disjuncts[i] = "(" + strings.Join(conjuncts, " AND ") + ")"
+	}
+
+	return strings.Join(disjuncts, " OR "), vars, nil
+}
+
+func cursorOf(sorts []query.CarSort, entity Car) *query.CarCursor {
+	values := make([]string, len(sorts))
+	for i, sort := range sorts {
+		values[i] = sortColumns[sort.Field].value(entity)
+	}
+
+	return &query.CarCursor{Values: values}
+}
+
+func sortArg(column sortColumn, value string) (interface{}, error) {
+	if !column.numeric {
+		return value, nil
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", app_error.ErrInvalidArgument, "malformed cursor")
+	}
+
+	return number, nil
+}
+
+func direction(sort query.CarSort) string {
+	if sort.Desc {
+		return "desc"
+	}
+
+	return "asc"
+}
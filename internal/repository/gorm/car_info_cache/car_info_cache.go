@@ -0,0 +1,36 @@
+package car_info_cache
+
+import (
+	"encoding/json"
+	"time"
+
+	"effective_mobile_2/internal/dto/model"
+)
+
+type CarInfoCache struct {
+	RegNum    string    `gorm:"primaryKey;type:varchar(100)"`
+	CarInfo   *string   `gorm:"type:jsonb"`
+	NotFound  bool      `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+func (CarInfoCache) TableName() string {
+	return "car_info_cache"
+}
+
+func ToModel(entity CarInfoCache) model.CarInfoCache {
+	cache := model.CarInfoCache{
+		RegNum:    entity.RegNum,
+		NotFound:  entity.NotFound,
+		ExpiresAt: entity.ExpiresAt,
+	}
+
+	if entity.CarInfo != nil {
+		var carInfo model.CarInfo
+		if json.Unmarshal([]byte(*entity.CarInfo), &carInfo) == nil {
+			cache.CarInfo = &carInfo
+		}
+	}
+
+	return cache
+}
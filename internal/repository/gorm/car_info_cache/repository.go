@@ -0,0 +1,99 @@
+package car_info_cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository keeps the car info cache in Postgres, shared by every
+// instance of the app and kept over restarts.
+type Repository struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// conn binds db to ctx. The cache stays out of the transaction of ctx: a
+// lookup may outlive it, and a rolled back batch does not make the car
+// info it looked up wrong.
+func (r *Repository) conn(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx)
+}
+
+// Get returns the cached lookup of qry.RegNum unless it expired.
+func (r *Repository) Get(ctx context.Context, qry *query.CarInfoCacheGet) (*model.CarInfoCache, error) {
+	const op = "repository.gorm.car_info_cache.Get"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("searching cached car info")
+
+	var entity CarInfoCache
+	result := r.conn(ctx).
+		Where("reg_num = ? AND expires_at > ?", qry.RegNum, time.Now()).
+		Take(&entity)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s - %s", app_error.ErrNotFound, "no cached car info for regNum", qry.RegNum)
+		}
+		log.Error("failed to search cached car info", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	cache := ToModel(entity)
+
+	log.Debug("searched cached car info", slog.Any("cache", cache))
+
+	return &cache, nil
+}
+
+// Set caches the lookup of qry.RegNum, replacing the one cached before.
+func (r *Repository) Set(ctx context.Context, qry *query.CarInfoCacheSet) error {
+	const op = "repository.gorm.car_info_cache.Set"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("caching car info")
+
+	entity := CarInfoCache{
+		RegNum:    qry.RegNum,
+		NotFound:  qry.NotFound,
+		ExpiresAt: qry.ExpiresAt,
+	}
+	if qry.CarInfo != nil {
+		carInfo, err := json.Marshal(qry.CarInfo)
+		if err != nil {
+			log.Error("failed to marshal car info", slog.String("error", err.Error()))
+			return err
+		}
+		value := string(carInfo)
+		entity.CarInfo = &value
+	}
+	result := r.conn(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(&entity)
+	if result.Error != nil {
+		log.Error("failed to cache car info", slog.String("error", result.Error.Error()))
+		return fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+
+	log.Debug("cached car info")
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package car_info_cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/database/dbtest"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+	"effective_mobile_2/internal/repository/gorm/car_info_cache"
+	"gorm.io/gorm"
+)
+
+func TestGetSet(t *testing.T) {
+	db := dbtest.Open(t)
+
+	year := 2002
+	carInfo := &model.CarInfo{Mark: "Lada", Model: "Vesta", Year: &year, Sources: map[string]string{"mark": "default"}}
+
+	tests := []struct {
+		name    string
+		sets    []query.CarInfoCacheSet
+		want    *model.CarInfoCache
+		wantErr error
+	}{
+		{
+			name:    "not cached",
+			wantErr: app_error.ErrNotFound,
+		},
+		{
+			name: "car info cached",
+			sets: []query.CarInfoCacheSet{{RegNum: "X123XX150", CarInfo: carInfo, ExpiresAt: time.Now().Add(time.Hour)}},
+			want: &model.CarInfoCache{RegNum: "X123XX150", CarInfo: carInfo},
+		},
+		{
+			name: "not found cached over the car info",
+			sets: []query.CarInfoCacheSet{
+				{RegNum: "X123XX150", CarInfo: carInfo, ExpiresAt: time.Now().Add(time.Hour)},
+				{RegNum: "X123XX150", NotFound: true, ExpiresAt: time.Now().Add(time.Hour)},
+			},
+			want: &model.CarInfoCache{RegNum: "X123XX150", NotFound: true},
+		},
+		{
+			name:    "expired",
+			sets:    []query.CarInfoCacheSet{{RegNum: "X123XX150", CarInfo: carInfo, ExpiresAt: time.Now().Add(-time.Second)}},
+			wantErr: app_error.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			dbtest.Rollback(t, db, func(tx *gorm.DB) {
+				ctx, repository := context.Background(), car_info_cache.New(tx)
+
+				for _, qry := range tt.sets {
+					qry := qry
+					if err := repository.Set(ctx, &qry); err != nil {
+						t.Fatalf("failed to cache car info: %v", err)
+					}
+				}
+
+				got, err := repository.Get(ctx, &query.CarInfoCacheGet{RegNum: "X123XX150"})
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				if tt.want == nil {
+					return
+				}
+				if got.NotFound != tt.want.NotFound || (got.CarInfo == nil) != (tt.want.CarInfo == nil) {
+					t.Fatalf("got %+v, want %+v", got, tt.want)
+				}
+				if got.CarInfo != nil && (got.CarInfo.Model != carInfo.Model || *got.CarInfo.Year != year || got.CarInfo.Sources["mark"] != "default") {
+					t.Errorf("got car info %+v, want %+v", got.CarInfo, carInfo)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package car_ownership
+
+import (
+	"time"
+
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/repository/gorm/car"
+	"effective_mobile_2/internal/repository/gorm/people"
+)
+
+// CarOwnership is a period during which a people owned a car. OwnedFrom is
+// nil for periods that started before the history was kept, OwnedTo is nil
+// for the current owner.
+type CarOwnership struct {
+	ID        uint          `gorm:"primary_key"`
+	Car       car.Car       `gorm:"foreignKey:CarID;constraint:OnDelete:CASCADE"`
+	CarID     uint          `gorm:"not null;index"`
+	Owner     people.People `gorm:"foreignKey:OwnerID"`
+	OwnerID   uint          `gorm:"not null;index"`
+	OwnedFrom *time.Time
+	OwnedTo   *time.Time
+}
+
+func ToModel(entity CarOwnership) model.CarOwnership {
+	ownership := model.CarOwnership{
+		ID:        entity.ID,
+		CarID:     entity.CarID,
+		OwnerID:   entity.OwnerID,
+		OwnedFrom: entity.OwnedFrom,
+		OwnedTo:   entity.OwnedTo,
+	}
+
+	if entity.Owner.ID != 0 {
+		owner := people.ToModel(entity.Owner)
+		ownership.Owner = &owner
+	}
+
+	return ownership
+}
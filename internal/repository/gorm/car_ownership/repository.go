@@ -0,0 +1,178 @@
+package car_ownership
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+	"effective_mobile_2/internal/repository/gorm/transaction"
+	"gorm.io/gorm"
+)
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) conn(ctx context.Context) *gorm.DB {
+	return transaction.Conn(ctx, r.db)
+}
+
+func (r *Repository) List(ctx context.Context, qry *query.CarOwnershipList) (*[]model.CarOwnership, error) {
+	const op = "repository.gorm.car_ownership.List"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("searching car ownerships")
+
+	var entities []CarOwnership
+	result := r.conn(ctx).
+		Preload("Owner").
+		Where("car_id = ?", qry.CarID).
+		Order("owned_from NULLS FIRST, id").
+		Find(&entities)
+	if result.Error != nil {
+		log.Error("failed to search car ownerships", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	ownerships := make([]model.CarOwnership, len(entities))
+	for i, entity := range entities {
+		ownerships[i] = ToModel(entity)
+	}
+
+	log.Debug("searched car ownerships", slog.Any("ownerships", ownerships))
+
+	return &ownerships, nil
+}
+
+// At returns the ownership of the car covering the given time. A period of
+// unknown start covers everything before its end.
+func (r *Repository) At(ctx context.Context, qry *query.CarOwnershipAt) (*model.CarOwnership, error) {
+	const op = "repository.gorm.car_ownership.At"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("searching car ownership")
+
+	var entities []CarOwnership
+	result := r.conn(ctx).
+		Preload("Owner").
+		Where("car_id = ?", qry.CarID).
+		Where("owned_from IS NULL OR owned_from <= ?", qry.At).
+		Where("owned_to IS NULL OR owned_to > ?", qry.At).
+		Order("owned_from DESC NULLS LAST, id DESC").
+		Limit(1).
+		Find(&entities)
+	if result.Error != nil {
+		log.Error("failed to search car ownership", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	if len(entities) == 0 {
+		log.Error("failed to search car ownership")
+		return nil, fmt.Errorf("%w: %s - %s", app_error.ErrNotFound, "car has no owner at", qry.At)
+	}
+	ownership := ToModel(entities[0])
+
+	log.Debug("searched car ownership", slog.Any("ownership", ownership))
+
+	return &ownership, nil
+}
+
+func (r *Repository) Open(ctx context.Context, qry *query.CarOwnershipOpen) (*model.CarOwnership, error) {
+	const op = "repository.gorm.car_ownership.Open"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("opening car ownership")
+
+	entity := CarOwnership{
+		CarID:     qry.CarID,
+		OwnerID:   qry.OwnerID,
+		OwnedFrom: &qry.OwnedFrom,
+	}
+	result := r.conn(ctx).Omit("Car", "Owner").Create(&entity)
+	if result.Error != nil {
+		log.Error("failed to open car ownership", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	ownership := ToModel(entity)
+
+	log.Debug("opened car ownership", slog.Any("ownership", ownership))
+
+	return &ownership, nil
+}
+
+// Close ends the current ownership of the car. The end may not precede the
+// start of the period.
+func (r *Repository) Close(ctx context.Context, qry *query.CarOwnershipClose) error {
+	const op = "repository.gorm.car_ownership.Close"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("closing car ownership")
+
+	var entities []CarOwnership
+	result := r.conn(ctx).Where("car_id = ? AND owned_to IS NULL", qry.CarID).Find(&entities)
+	if result.Error != nil {
+		log.Error("failed to search current car ownership", slog.String("error", result.Error.Error()))
+		return fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	for _, entity := range entities {
+		if entity.OwnedFrom != nil && entity.OwnedFrom.After(qry.OwnedTo) {
+			log.Error("failed to close car ownership before it started")
+			return fmt.Errorf("%w: %s - %s", app_error.ErrInvalidArgument, "ownership starts after", qry.OwnedTo)
+		}
+	}
+	result = r.conn(ctx).
+		Model(&CarOwnership{}).
+		Where("car_id = ? AND owned_to IS NULL", qry.CarID).
+		Update("owned_to", qry.OwnedTo)
+	if result.Error != nil {
+		log.Error("failed to close car ownership", slog.String("error", result.Error.Error()))
+		return fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+
+	log.Debug("closed car ownership", slog.Int64("count", result.RowsAffected))
+
+	return nil
+}
+
+// ReassignOwner moves the ownership history of qry.FromOwnerIDs to
+// qry.ToOwnerID, used when duplicate peoples are merged.
+func (r *Repository) ReassignOwner(ctx context.Context, qry *query.CarOwnershipReassignOwner) (int64, error) {
+	const op = "repository.gorm.car_ownership.ReassignOwner"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("reassigning car ownerships")
+
+	result := r.conn(ctx).
+		Model(&CarOwnership{}).
+		Where("owner_id IN ?", qry.FromOwnerIDs).
+		Update("owner_id", qry.ToOwnerID)
+	if result.Error != nil {
+		log.Error("failed to reassign car ownerships", slog.String("error", result.Error.Error()))
+		return 0, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+
+	log.Debug("reassigned car ownerships", slog.Int64("count", result.RowsAffected))
+
+	return result.RowsAffected, nil
+}
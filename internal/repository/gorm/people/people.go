@@ -1,24 +1,39 @@
 package people
 
-import "effective_mobile_2/internal/dto/model"
+import (
+	"time"
+
+	"effective_mobile_2/internal/dto/model"
+)
 
 type People struct {
-	ID         uint   `gorm:"primary_key"`
-	Name       string `gorm:"type:varchar(100)"`
-	Surname    string `gorm:"type:varchar(100)"`
-	Patronymic string `gorm:"type:varchar(100);default:null"`
+	ID         uint    `gorm:"primary_key"`
+	Name       string  `gorm:"type:varchar(100)"`
+	Surname    string  `gorm:"type:varchar(100)"`
+	Patronymic string  `gorm:"type:varchar(100);default:null"`
+	ExternalID *string `gorm:"type:varchar(100);uniqueIndex"`
+	FullName   string  `gorm:"->;-:migration"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
 
 func ToModel(entity People) model.People {
 	people := model.People{
-		ID:      entity.ID,
-		Name:    entity.Name,
-		Surname: entity.Surname,
+		ID:         entity.ID,
+		Name:       entity.Name,
+		Surname:    entity.Surname,
+		ExternalID: entity.ExternalID,
 	}
 
 	if entity.Patronymic != "" {
 		people.Patronymic = &entity.Patronymic
 	}
+	if !entity.CreatedAt.IsZero() {
+		people.CreatedAt = &entity.CreatedAt
+	}
+	if !entity.UpdatedAt.IsZero() {
+		people.UpdatedAt = &entity.UpdatedAt
+	}
 
 	return people
 }
@@ -1,16 +1,24 @@
 package people
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"effective_mobile_2/internal/app_error"
 	"effective_mobile_2/internal/app_log"
 	"effective_mobile_2/internal/dto/model"
 	"effective_mobile_2/internal/dto/query"
+	"effective_mobile_2/internal/repository/gorm/transaction"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
+// foreignKeyViolation is the Postgres error code of a foreign key violation.
+const foreignKeyViolation = "23503"
+
 type Repository struct {
 	db *gorm.DB
 }
@@ -19,7 +27,73 @@ func New(db *gorm.DB) *Repository {
 	return &Repository{db: db}
 }
 
-func (r *Repository) Create(qry *query.PeopleCreate) (*model.People, error) {
+func (r *Repository) conn(ctx context.Context) *gorm.DB {
+	return transaction.Conn(ctx, r.db)
+}
+
+func (r *Repository) List(ctx context.Context, qry *query.PeopleList) (*[]model.People, error) {
+	const op = "repository.gorm.people.List"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("searching peoples")
+
+	builder := r.conn(ctx).Model(&People{})
+	if qry.Name != nil {
+		builder = builder.Where(contains("name"), "%"+escapeLike(*qry.Name)+"%")
+	}
+	if qry.Surname != nil {
+		builder = builder.Where(contains("surname"), "%"+escapeLike(*qry.Surname)+"%")
+	}
+	if qry.Patronymic != nil {
+		builder = builder.Where(contains("patronymic"), "%"+escapeLike(*qry.Patronymic)+"%")
+	}
+	builder = builder.Order(fmt.Sprintf("id %s", qry.Order))
+	builder = builder.Limit(qry.Count).Offset((qry.Page - 1) * qry.Count)
+	var entities []People
+	result := builder.Find(&entities)
+	if result.Error != nil {
+		log.Error("failed to search peoples", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	peoples := make([]model.People, len(entities))
+	for i, entity := range entities {
+		peoples[i] = ToModel(entity)
+	}
+
+	log.Debug("searched peoples", slog.Any("peoples", peoples))
+
+	return &peoples, nil
+}
+
+func (r *Repository) Get(ctx context.Context, qry *query.PeopleGet) (*model.People, error) {
+	const op = "repository.gorm.people.Get"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("searching people")
+
+	var entity People
+	result := r.conn(ctx).First(&entity, qry.ID)
+	if result.Error != nil {
+		log.Error("failed to search", slog.String("error", result.Error.Error()))
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s - %d", app_error.ErrNotFound, "failed to search by id", qry.ID)
+		}
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	people := ToModel(entity)
+
+	log.Debug("searched people", slog.Any("people", people))
+
+	return &people, nil
+}
+
+func (r *Repository) Create(ctx context.Context, qry *query.PeopleCreate) (*model.People, error) {
 	const op = "repository.gorm.people.Create"
 	log := app_log.Logger().With(
 		slog.String("op", op),
@@ -29,13 +103,14 @@ func (r *Repository) Create(qry *query.PeopleCreate) (*model.People, error) {
 	log.Info("creating people")
 
 	entity := People{
-		Name:    qry.Name,
-		Surname: qry.Surname,
+		Name:       qry.Name,
+		Surname:    qry.Surname,
+		ExternalID: qry.ExternalID,
 	}
 	if qry.Patronymic != nil {
 		entity.Patronymic = *qry.Patronymic
 	}
-	result := r.db.Create(&entity)
+	result := r.conn(ctx).Create(&entity)
 	if result.Error != nil {
 		log.Error("failed to create people", slog.String("error", result.Error.Error()))
 		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
@@ -46,3 +121,245 @@ func (r *Repository) Create(qry *query.PeopleCreate) (*model.People, error) {
 
 	return &people, nil
 }
+
+// FindOrCreate returns the people matching qry by external ID or, failing
+// that, by case, accent and whitespace insensitive full name among the
+// peoples without a different external ID, and creates one when nothing
+// matches. Concurrent calls for the same full name are
+// serialized when run inside a transaction.
+func (r *Repository) FindOrCreate(ctx context.Context, qry *query.PeopleCreate) (*model.People, error) {
+	const op = "repository.gorm.people.FindOrCreate"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("searching people")
+
+	name, surname := normalizeSpaces(qry.Name), normalizeSpaces(qry.Surname)
+	patronymic := ""
+	if qry.Patronymic != nil {
+		patronymic = normalizeSpaces(*qry.Patronymic)
+	}
+
+	var entities []People
+	if qry.ExternalID != nil {
+		result := r.conn(ctx).Where("external_id = ?", *qry.ExternalID).Limit(1).Find(&entities)
+		if result.Error != nil {
+			log.Error("failed to search by external id", slog.String("error", result.Error.Error()))
+			return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+		}
+		if len(entities) > 0 {
+			people := ToModel(entities[0])
+			log.Debug("found people by external id", slog.Any("people", people))
+			return &people, nil
+		}
+	}
+
+	key := surname + "|" + name + "|" + patronymic
+	result := r.conn(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext("+normalize("?")+"))", key)
+	if result.Error != nil {
+		log.Error("failed to lock full name", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	builder := r.conn(ctx).
+		Where(normalize(squish("name"))+" = "+normalize("?"), name).
+		Where(normalize(squish("surname"))+" = "+normalize("?"), surname).
+		Where(normalize(squish("COALESCE(patronymic, '')"))+" = "+normalize("?"), patronymic)
+	if qry.ExternalID != nil {
+		// a namesake the registry knows under another ID is someone else
+		builder = builder.Where("(external_id IS NULL OR external_id = ?)", *qry.ExternalID)
+	}
+	result = builder.
+		Order("id").
+		Limit(1).
+		Find(&entities)
+	if result.Error != nil {
+		log.Error("failed to search by full name", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	if len(entities) > 0 {
+		entity := entities[0]
+		if qry.ExternalID != nil && entity.ExternalID == nil {
+			entity.ExternalID = qry.ExternalID
+			if result = r.conn(ctx).Model(&entity).Update("external_id", entity.ExternalID); result.Error != nil {
+				log.Error("failed to set external id", slog.String("error", result.Error.Error()))
+				return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+			}
+		}
+		people := ToModel(entity)
+		log.Debug("found people by full name", slog.Any("people", people))
+		return &people, nil
+	}
+
+	log.Info("people not found")
+
+	create := query.PeopleCreate{
+		Name:       name,
+		Surname:    surname,
+		ExternalID: qry.ExternalID,
+	}
+	if patronymic != "" {
+		create.Patronymic = &patronymic
+	}
+
+	return r.Create(ctx, &create)
+}
+
+// Duplicates groups peoples sharing the same normalized full name, the
+// groups with the most members first.
+func (r *Repository) Duplicates(ctx context.Context, qry *query.PeopleDuplicates) (*[]model.PeopleDuplicate, error) {
+	const op = "repository.gorm.people.Duplicates"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("searching duplicates")
+
+	var fullNames []string
+	result := r.conn(ctx).
+		Model(&People{}).
+		Select(fullNameExpr+" AS full_name").
+		Group("full_name").
+		Having("count(*) > 1").
+		Order("count(*) desc, full_name").
+		Limit(qry.Count).
+		Offset((qry.Page-1)*qry.Count).
+		Pluck("full_name", &fullNames)
+	if result.Error != nil {
+		log.Error("failed to group duplicates", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+
+	duplicates := make([]model.PeopleDuplicate, len(fullNames))
+	if len(fullNames) == 0 {
+		log.Debug("searched duplicates", slog.Any("duplicates", duplicates))
+		return &duplicates, nil
+	}
+
+	var entities []People
+	result = r.conn(ctx).
+		Select("*, "+fullNameExpr+" AS full_name").
+		Where(fullNameExpr+" IN ?", fullNames).
+		Order("id").
+		Find(&entities)
+	if result.Error != nil {
+		log.Error("failed to search duplicates", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	groups := make(map[string]int, len(fullNames))
+	for i, fullName := range fullNames {
+		groups[fullName] = i
+		duplicates[i] = model.PeopleDuplicate{FullName: fullName, Peoples: make([]model.People, 0)}
+	}
+	for _, entity := range entities {
+		i := groups[entity.FullName]
+		duplicates[i].Peoples = append(duplicates[i].Peoples, ToModel(entity))
+	}
+
+	log.Debug("searched duplicates", slog.Any("duplicates", duplicates))
+
+	return &duplicates, nil
+}
+
+func (r *Repository) Update(ctx context.Context, qry *query.PeopleUpdate) (*model.People, error) {
+	const op = "repository.gorm.people.Update"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("searching people")
+
+	var entity People
+	result := r.conn(ctx).First(&entity, qry.ID)
+	if result.Error != nil {
+		log.Error("failed to search", slog.String("error", result.Error.Error()))
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s - %d", app_error.ErrNotFound, "failed to search by id", qry.ID)
+		}
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+
+	log.Debug("searched people", slog.Any("entity", entity))
+	log.Info("updating people", slog.Any("entity", entity))
+
+	if qry.Name != nil {
+		entity.Name = *qry.Name
+	}
+	if qry.Surname != nil {
+		entity.Surname = *qry.Surname
+	}
+	if qry.Patronymic != nil {
+		entity.Patronymic = *qry.Patronymic
+	}
+	result = r.conn(ctx).Save(&entity)
+	if result.Error != nil {
+		log.Error("failed to update people", slog.String("error", result.Error.Error()))
+		return nil, fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	people := ToModel(entity)
+
+	log.Debug("updated people", slog.Any("people", people))
+
+	return &people, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, qry *query.PeopleDelete) error {
+	const op = "repository.gorm.people.Delete"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	log.Info("deleting people")
+
+	result := r.conn(ctx).Delete(&People{}, qry.ID)
+	if result.Error != nil {
+		log.Error("failed to delete people", slog.String("error", result.Error.Error()))
+		var pgErr *pgconn.PgError
+		if errors.As(result.Error, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return fmt.Errorf("%w: %s - %d", app_error.ErrConflict, "people owns or owned cars", qry.ID)
+		}
+		return fmt.Errorf("%w: %w", app_error.ErrDatabase, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		log.Error("failed to delete people")
+		return fmt.Errorf("%w: %s - %d", app_error.ErrNotFound, "failed to delete by id", qry.ID)
+	}
+
+	log.Debug("deleted people")
+
+	return nil
+}
+
+// fullNameExpr is the normalized full name used to match the same person,
+// it follows the comparison made by FindOrCreate.
+var fullNameExpr = normalize(squish("surname")) + " || ' ' || " +
+	normalize(squish("name")) + " || ' ' || " +
+	normalize(squish("COALESCE(patronymic, '')"))
+
+func normalize(expr string) string {
+	return "f_unaccent(lower(" + expr + "))"
+}
+
+// squish trims expr and collapses its inner whitespace like normalizeSpaces,
+// it must stay in sync with idx_peoples_full_name_squished.
+func squish(expr string) string {
+	return "regexp_replace(btrim(" + expr + `), '\s+', ' ', 'g')`
+}
+
+// contains matches expr the way the car filters do, case and accent
+// insensitive, backed by the trigram indexes created in database.Migrate.
+func contains(expr string) string {
+	return normalize(expr) + " LIKE " + normalize("?")
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func normalizeSpaces(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,122 @@
+package people_test
+
+import (
+	"context"
+	"testing"
+
+	"effective_mobile_2/internal/database/dbtest"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+	"effective_mobile_2/internal/repository/gorm/people"
+	"gorm.io/gorm"
+)
+
+func ptr(value string) *string {
+	return &value
+}
+
+func mustCreate(t *testing.T, ctx context.Context, repository *people.Repository, qry query.PeopleCreate) *model.People {
+	t.Helper()
+
+	created, err := repository.Create(ctx, &qry)
+	if err != nil {
+		t.Fatalf("failed to create people: %v", err)
+	}
+
+	return created
+}
+
+func mustFindOrCreate(t *testing.T, ctx context.Context, repository *people.Repository, qry query.PeopleCreate) *model.People {
+	t.Helper()
+
+	found, err := repository.FindOrCreate(ctx, &qry)
+	if err != nil {
+		t.Fatalf("failed to find or create people: %v", err)
+	}
+
+	return found
+}
+
+func TestFindOrCreatePrefersExternalID(t *testing.T) {
+	db := dbtest.Open(t)
+
+	dbtest.Rollback(t, db, func(tx *gorm.DB) {
+		ctx, repository := context.Background(), people.New(tx)
+		mustCreate(t, ctx, repository, query.PeopleCreate{Name: "Ivan", Surname: "Dedupetest", ExternalID: ptr("dedupe-test-1")})
+		other := mustCreate(t, ctx, repository, query.PeopleCreate{Name: "Petr", Surname: "Dedupetest", ExternalID: ptr("dedupe-test-2")})
+
+		found := mustFindOrCreate(t, ctx, repository, query.PeopleCreate{Name: "Ivan", Surname: "Dedupetest", ExternalID: ptr("dedupe-test-2")})
+		if found.ID != other.ID {
+			t.Errorf("got people %d, want %d known by the external id", found.ID, other.ID)
+		}
+	})
+}
+
+func TestFindOrCreateKeepsNamesakesWithOtherExternalIDApart(t *testing.T) {
+	db := dbtest.Open(t)
+
+	dbtest.Rollback(t, db, func(tx *gorm.DB) {
+		ctx, repository := context.Background(), people.New(tx)
+		namesake := mustCreate(t, ctx, repository, query.PeopleCreate{Name: "Ivan", Surname: "Dedupetest", ExternalID: ptr("dedupe-test-1")})
+
+		found := mustFindOrCreate(t, ctx, repository, query.PeopleCreate{Name: "Ivan", Surname: "Dedupetest", ExternalID: ptr("dedupe-test-3")})
+		if found.ID == namesake.ID {
+			t.Fatalf("got namesake %d known under another external id", namesake.ID)
+		}
+		if found.ExternalID == nil || *found.ExternalID != "dedupe-test-3" {
+			t.Errorf("got external id %v, want dedupe-test-3", found.ExternalID)
+		}
+	})
+}
+
+func TestFindOrCreateAdoptsNamesakeWithoutExternalID(t *testing.T) {
+	db := dbtest.Open(t)
+
+	dbtest.Rollback(t, db, func(tx *gorm.DB) {
+		ctx, repository := context.Background(), people.New(tx)
+		namesake := mustCreate(t, ctx, repository, query.PeopleCreate{Name: "Ivan", Surname: "Dedupetest"})
+
+		found := mustFindOrCreate(t, ctx, repository, query.PeopleCreate{Name: "Ivan", Surname: "Dedupetest", ExternalID: ptr("dedupe-test-4")})
+		if found.ID != namesake.ID {
+			t.Fatalf("got people %d, want namesake %d", found.ID, namesake.ID)
+		}
+		if found.ExternalID == nil || *found.ExternalID != "dedupe-test-4" {
+			t.Errorf("got external id %v, want dedupe-test-4", found.ExternalID)
+		}
+	})
+}
+
+func TestFindOrCreateFoldsFullName(t *testing.T) {
+	db := dbtest.Open(t)
+
+	tests := []struct {
+		name string
+		qry  query.PeopleCreate
+	}{
+		{"case", query.PeopleCreate{Name: "ANNA MARIA", Surname: "ЁЛКИНА-DEDUPETEST", Patronymic: ptr("петровна")}},
+		{"accents", query.PeopleCreate{Name: "Anna Mariá", Surname: "Елкина-Dedupetest", Patronymic: ptr("Петровна")}},
+		{"spaces", query.PeopleCreate{Name: " Anna   Maria ", Surname: "Ёлкина-Dedupetest ", Patronymic: ptr("  Петровна")}},
+	}
+
+	dbtest.Rollback(t, db, func(tx *gorm.DB) {
+		ctx, repository := context.Background(), people.New(tx)
+		stored := mustCreate(t, ctx, repository, query.PeopleCreate{Name: "Anna  Maria", Surname: " Ёлкина-Dedupetest", Patronymic: ptr("Петровна ")})
+
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.name, func(t *testing.T) {
+				found := mustFindOrCreate(t, ctx, repository, tt.qry)
+				if found.ID != stored.ID {
+					t.Errorf("got people %d, want %d", found.ID, stored.ID)
+				}
+			})
+		}
+
+		t.Run("other patronymic", func(t *testing.T) {
+			qry := query.PeopleCreate{Name: "Anna Maria", Surname: "Ёлкина-Dedupetest", Patronymic: ptr("Ивановна")}
+			if found := mustFindOrCreate(t, ctx, repository, qry); found.ID == stored.ID {
+				t.Errorf("got people %d for another patronymic", found.ID)
+			}
+		})
+	})
+}
@@ -0,0 +1,57 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/app_log"
+	"gorm.io/gorm"
+)
+
+type ctxKey struct{}
+
+type Manager struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *Manager {
+	return &Manager{db: db}
+}
+
+// Transaction runs fn inside a database transaction. The transaction travels
+// in the context passed to fn, so every gorm repository called with that
+// context takes part in it. It is committed when fn returns nil and rolled
+// back otherwise. Called within another transaction it opens a savepoint,
+// so only the work of fn is rolled back on failure.
+func (m *Manager) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "repository.gorm.transaction.Transaction"
+	log := app_log.Logger().With(slog.String("op", op))
+
+	var fnErr error
+	err := Conn(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		fnErr = fn(context.WithValue(ctx, ctxKey{}, tx))
+		return fnErr
+	})
+	if err != nil {
+		if fnErr != nil {
+			log.Debug("rolled back transaction", slog.String("error", fnErr.Error()))
+			return fnErr
+		}
+		log.Error("failed to commit transaction", slog.String("error", err.Error()))
+		return fmt.Errorf("%w: %w", app_error.ErrDatabase, err)
+	}
+
+	return nil
+}
+
+// Conn returns the transaction carried by ctx, or db bound to ctx when there
+// is none.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(ctxKey{}).(*gorm.DB); ok {
+		return tx
+	}
+
+	return db.WithContext(ctx)
+}
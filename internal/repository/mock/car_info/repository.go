@@ -1,6 +1,7 @@
 package car_info
 
 import (
+	"context"
 	"log/slog"
 	"time"
 
@@ -16,7 +17,7 @@ func New() *Repository {
 	return &Repository{}
 }
 
-func (r *Repository) GetCarInfo(qry *query.CarInfo) (*model.CarInfo, error) {
+func (r *Repository) GetCarInfo(ctx context.Context, qry *query.CarInfo) (*model.CarInfo, error) {
 	const op = "repository.mock.car_info.GetCarInfo"
 	log := app_log.Logger().With(
 		slog.String("op", op),
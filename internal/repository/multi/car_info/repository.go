@@ -0,0 +1,182 @@
+package car_info
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+)
+
+type carInfoRepository interface {
+	GetCarInfo(ctx context.Context, qry *query.CarInfo) (*model.CarInfo, error)
+}
+
+type carInfoBreaker interface {
+	State() model.CircuitBreaker
+}
+
+var carInfoFields = []string{
+	model.CarInfoFieldMark,
+	model.CarInfoFieldModel,
+	model.CarInfoFieldYear,
+	model.CarInfoFieldOwner,
+}
+
+// Provider is a car info repository tried in order of Priority, the lowest
+// first. When merging, only its Fields are taken, all of them when empty.
+type Provider struct {
+	Name       string
+	Priority   int
+	Fields     []string
+	Repository carInfoRepository
+}
+
+// Repository resolves car info through several providers. Without merge
+// the first provider that knows the car supplies it, the others are only
+// asked when it fails or does not know the car. With merge the providers
+// are asked in turn until every field is filled, each field taken from the
+// first provider that has it; a car info left incomplete by a provider that
+// failed is that failure.
+type Repository struct {
+	providers []Provider
+	merge     bool
+}
+
+func New(providers []Provider, merge bool) *Repository {
+	providers = slices.Clone(providers)
+	slices.SortStableFunc(providers, func(a, b Provider) int {
+		return cmp.Compare(a.Priority, b.Priority)
+	})
+
+	return &Repository{providers: providers, merge: merge}
+}
+
+func (r *Repository) GetCarInfo(ctx context.Context, qry *query.CarInfo) (*model.CarInfo, error) {
+	const op = "repository.multi.car_info.GetCarInfo"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+	)
+
+	var merged *model.CarInfo
+	var failure error
+	for _, provider := range r.providers {
+		carInfo, err := provider.Repository.GetCarInfo(ctx, qry)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			log.Error("provider failed", slog.String("provider", provider.Name), slog.String("error", err.Error()))
+			// the car is only not found when no provider failed to answer
+			if failure == nil || errors.Is(failure, app_error.ErrNotFound) {
+				failure = err
+			}
+			continue
+		}
+
+		if !r.merge {
+			carInfo.Sources = sources(carInfo, carInfoFields, provider.Name)
+			log.Debug("got car info", slog.String("provider", provider.Name))
+			return carInfo, nil
+		}
+		merged = mergeCarInfo(merged, carInfo, provider)
+		if complete(merged) {
+			break
+		}
+	}
+
+	// a partial car info is only the answer when the providers that could
+	// fill the rest do not know the car, a failed one may know it later
+	partial := failure == nil || errors.Is(failure, app_error.ErrNotFound)
+	if merged != nil && len(merged.Sources) > 0 && (complete(merged) || partial) {
+		log.Debug("merged car info", slog.Any("sources", merged.Sources))
+		return merged, nil
+	}
+	if failure == nil {
+		failure = fmt.Errorf("%w: %s - %s", app_error.ErrNotFound, "failed to get car info by regNum", qry.RegNum)
+	}
+
+	return nil, failure
+}
+
+// Breakers reports the state of the providers behind a circuit breaker by
+// provider name.
+func (r *Repository) Breakers() map[string]model.CircuitBreaker {
+	breakers := map[string]model.CircuitBreaker{}
+	for _, provider := range r.providers {
+		if breaker, ok := provider.Repository.(carInfoBreaker); ok {
+			breakers[provider.Name] = breaker.State()
+		}
+	}
+
+	return breakers
+}
+
+// mergeCarInfo fills the fields of merged that are still empty from the
+// car info of provider, recording it as their source.
+func mergeCarInfo(merged *model.CarInfo, carInfo *model.CarInfo, provider Provider) *model.CarInfo {
+	if merged == nil {
+		merged = &model.CarInfo{Sources: map[string]string{}}
+	}
+	fields := provider.Fields
+	if len(fields) == 0 {
+		fields = carInfoFields
+	}
+
+	for field := range sources(carInfo, fields, provider.Name) {
+		if _, ok := merged.Sources[field]; ok {
+			continue
+		}
+		switch field {
+		case model.CarInfoFieldMark:
+			merged.Mark = carInfo.Mark
+		case model.CarInfoFieldModel:
+			merged.Model = carInfo.Model
+		case model.CarInfoFieldYear:
+			merged.Year = carInfo.Year
+		case model.CarInfoFieldOwner:
+			merged.Owner = carInfo.Owner
+		}
+		merged.Sources[field] = provider.Name
+	}
+
+	return merged
+}
+
+// sources names provider as the source of the given fields carInfo has.
+func sources(carInfo *model.CarInfo, fields []string, provider string) map[string]string {
+	sources := map[string]string{}
+	for _, field := range fields {
+		if has(carInfo, field) {
+			sources[field] = provider
+		}
+	}
+
+	return sources
+}
+
+func has(carInfo *model.CarInfo, field string) bool {
+	switch field {
+	case model.CarInfoFieldMark:
+		return carInfo.Mark != ""
+	case model.CarInfoFieldModel:
+		return carInfo.Model != ""
+	case model.CarInfoFieldYear:
+		return carInfo.Year != nil
+	case model.CarInfoFieldOwner:
+		return carInfo.Owner != nil
+	}
+
+	return false
+}
+
+func complete(carInfo *model.CarInfo) bool {
+	return len(carInfo.Sources) == len(carInfoFields)
+}
@@ -0,0 +1,83 @@
+package car_info_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+	"effective_mobile_2/internal/repository/multi/car_info"
+)
+
+// provider answers every lookup with its car info or its error.
+type provider struct {
+	carInfo *model.CarInfo
+	err     error
+}
+
+func (p provider) GetCarInfo(_ context.Context, _ *query.CarInfo) (*model.CarInfo, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	carInfo := *p.carInfo
+
+	return &carInfo, nil
+}
+
+var (
+	errNotFound    = fmt.Errorf("%w: %s", app_error.ErrNotFound, "unknown car")
+	errUnavailable = fmt.Errorf("%w: %s", app_error.ErrHTTPRequestFailed, "request failed with status - 503")
+	vehicle        = &model.CarInfo{Mark: "Lada", Model: "Vesta"}
+	owner          = &model.CarInfo{Owner: &model.People{Name: "Ivan", Surname: "Ivanov"}}
+)
+
+func TestGetCarInfo(t *testing.T) {
+	app_log.Setup("error")
+
+	tests := []struct {
+		name      string
+		merge     bool
+		providers []provider
+		wantErr   error
+		want      map[string]string
+	}{
+		{"first that knows", false, []provider{{err: errNotFound}, {carInfo: vehicle}}, nil, map[string]string{"mark": "1", "model": "1"}},
+		{"falls back on failure", false, []provider{{err: errUnavailable}, {carInfo: vehicle}}, nil, map[string]string{"mark": "1", "model": "1"}},
+		{"failure beats not found", false, []provider{{err: errNotFound}, {err: errUnavailable}}, app_error.ErrHTTPRequestFailed, nil},
+		{"nobody knows", false, []provider{{err: errNotFound}, {err: errNotFound}}, app_error.ErrNotFound, nil},
+		{"merge", true, []provider{{carInfo: vehicle}, {carInfo: owner}}, nil, map[string]string{"mark": "0", "model": "0", "owner": "1"}},
+		{"merge partial when not found", true, []provider{{carInfo: vehicle}, {err: errNotFound}}, nil, map[string]string{"mark": "0", "model": "0"}},
+		{"merge fails when a failure left it partial", true, []provider{{carInfo: vehicle}, {err: errUnavailable}}, app_error.ErrHTTPRequestFailed, nil},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			providers := make([]car_info.Provider, len(tt.providers))
+			for i, p := range tt.providers {
+				providers[i] = car_info.Provider{Name: fmt.Sprint(i), Priority: i, Repository: p}
+			}
+			repository := car_info.New(providers, tt.merge)
+
+			carInfo, err := repository.GetCarInfo(context.Background(), &query.CarInfo{RegNum: "X123XX150"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(carInfo.Sources) != len(tt.want) {
+				t.Fatalf("got sources %v, want %v", carInfo.Sources, tt.want)
+			}
+			for field, source := range tt.want {
+				if carInfo.Sources[field] != source {
+					t.Errorf("got sources %v, want %v", carInfo.Sources, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package audit
+
+import (
+	"context"
+
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+)
+
+type auditRepository interface {
+	List(ctx context.Context, qry *query.AuditLogList) (*[]model.AuditLog, int64, error)
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/app_time"
+	"effective_mobile_2/internal/dto/command"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+)
+
+type Service struct {
+	auditRepository auditRepository
+}
+
+func New(auditRepository auditRepository) *Service {
+	return &Service{auditRepository: auditRepository}
+}
+
+func (s *Service) Index(ctx context.Context, cmd *command.AuditIndex) (*model.AuditLogList, error) {
+	const op = "service.audit.Index"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("searching audit logs")
+
+	qry := query.AuditLogList{
+		EntityType: cmd.EntityType,
+		EntityID:   cmd.EntityID,
+		Action:     cmd.Action,
+		Actor:      cmd.Actor,
+		RequestID:  cmd.RequestID,
+	}
+	if cmd.From != nil && *cmd.From != "" {
+		from, err := app_time.ParseDate(*cmd.From)
+		if err != nil {
+			log.Error("failed to parse from", slog.String("error", err.Error()))
+			return nil, err
+		}
+		qry.From = &from
+	}
+	if cmd.To != nil && *cmd.To != "" {
+		to, err := app_time.ParseDate(*cmd.To)
+		if err != nil {
+			log.Error("failed to parse to", slog.String("error", err.Error()))
+			return nil, err
+		}
+		qry.To = &to
+	}
+	if cmd.Page == nil || *cmd.Page <= 0 {
+		qry.Page = 1
+	} else {
+		qry.Page = *cmd.Page
+	}
+	if cmd.Count == nil || *cmd.Count <= 0 {
+		qry.Count = 10
+	} else {
+		qry.Count = *cmd.Count
+	}
+	auditLogs, total, err := s.auditRepository.List(ctx, &qry)
+	if err != nil {
+		log.Error("failed to search audit logs", slog.String("error", err.Error()))
+		return nil, err
+	}
+	auditLogList := model.AuditLogList{
+		Items:      *auditLogs,
+		Pagination: model.NewPagination(qry.Page, qry.Count, total),
+	}
+
+	log.Debug("searched audit logs", slog.Any("auditLogList", auditLogList))
+
+	return &auditLogList, nil
+}
@@ -0,0 +1,46 @@
+package car
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/dto/query"
+)
+
+func encodeCursor(cursor *query.CarCursor) (*string, error) {
+	if cursor == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+
+	return &encoded, nil
+}
+
+// decodeCursor turns an opaque cursor back into sort key values. An empty
+// cursor is valid and points to the beginning of the list.
+func decodeCursor(encoded string, keys int) (*query.CarCursor, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", app_error.ErrInvalidArgument, "malformed cursor")
+	}
+	var cursor query.CarCursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: %s", app_error.ErrInvalidArgument, "malformed cursor")
+	}
+	if len(cursor.Values) != keys {
+		return nil, fmt.Errorf("%w: %s", app_error.ErrInvalidArgument, "cursor does not match sort")
+	}
+
+	return &cursor, nil
+}
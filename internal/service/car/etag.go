@@ -0,0 +1,31 @@
+package car
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseIfMatch reads the car versions listed in an If-Match header, each an
+// ETag as set by the handlers. It returns nil for "*", which matches any
+// version, and skips the tags that are not versions. Weak tags are skipped
+// too, as If-Match compares strongly and a weak tag never matches.
+func parseIfMatch(header string) []uint {
+	if strings.TrimSpace(header) == "*" {
+		return nil
+	}
+
+	versions := make([]uint, 0)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		version, err := strconv.ParseUint(strings.Trim(tag, `"`), 10, 0)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, uint(version))
+	}
+
+	return versions
+}
@@ -1,21 +1,44 @@
 package car
 
 import (
+	"context"
+
 	"effective_mobile_2/internal/dto/model"
 	"effective_mobile_2/internal/dto/query"
 )
 
 type carRepository interface {
-	List(qry *query.CarList) (*[]model.Car, error)
-	Create(qry *query.CarCreate) (*model.Car, error)
-	Update(qry *query.CarUpdate) (*model.Car, error)
-	Delete(qry *query.CarDelete) error
+	List(ctx context.Context, qry *query.CarList) (*[]model.Car, int64, error)
+	Scroll(ctx context.Context, qry *query.CarList) (*[]model.Car, *query.CarCursor, error)
+	Get(ctx context.Context, qry *query.CarGet) (*model.Car, error)
+	ListByRegNums(ctx context.Context, qry *query.CarListByRegNums) (*[]model.Car, error)
+	Create(ctx context.Context, qry *query.CarCreate) (*model.Car, error)
+	Update(ctx context.Context, qry *query.CarUpdate) (*model.Car, error)
+	Delete(ctx context.Context, qry *query.CarDelete) error
+	Restore(ctx context.Context, qry *query.CarRestore) (*model.Car, error)
+}
+
+type transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type carInfoRepository interface {
-	GetCarInfo(qry *query.CarInfo) (*model.CarInfo, error)
+	GetCarInfo(ctx context.Context, qry *query.CarInfo) (*model.CarInfo, error)
 }
 
 type ownerRepository interface {
-	Create(qry *query.PeopleCreate) (*model.People, error)
+	Get(ctx context.Context, qry *query.PeopleGet) (*model.People, error)
+	FindOrCreate(ctx context.Context, qry *query.PeopleCreate) (*model.People, error)
+}
+
+type auditRepository interface {
+	Create(ctx context.Context, qry *query.AuditLogCreate) (*model.AuditLog, error)
+	List(ctx context.Context, qry *query.AuditLogList) (*[]model.AuditLog, int64, error)
+}
+
+type ownershipRepository interface {
+	List(ctx context.Context, qry *query.CarOwnershipList) (*[]model.CarOwnership, error)
+	At(ctx context.Context, qry *query.CarOwnershipAt) (*model.CarOwnership, error)
+	Open(ctx context.Context, qry *query.CarOwnershipOpen) (*model.CarOwnership, error)
+	Close(ctx context.Context, qry *query.CarOwnershipClose) error
 }
@@ -1,33 +1,61 @@
 package car
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
+	"effective_mobile_2/internal/app_audit"
+	"effective_mobile_2/internal/app_error"
 	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/app_time"
 	"effective_mobile_2/internal/dto/command"
 	"effective_mobile_2/internal/dto/model"
 	"effective_mobile_2/internal/dto/query"
+	"golang.org/x/sync/errgroup"
 )
 
 type Service struct {
-	carRepository     carRepository
-	carInfoRepository carInfoRepository
-	ownerRepository   ownerRepository
+	carRepository       carRepository
+	carInfoRepository   carInfoRepository
+	ownerRepository     ownerRepository
+	ownershipRepository ownershipRepository
+	auditRepository     auditRepository
+	transactor          transactor
+	carInfoConcurrency  int
+	ifMatchRequired     bool
 }
 
 func New(
 	carRepository carRepository,
 	carInfoRepository carInfoRepository,
 	ownerRepository ownerRepository,
+	ownershipRepository ownershipRepository,
+	auditRepository auditRepository,
+	transactor transactor,
+	carInfoConcurrency int,
+	ifMatchRequired bool,
 ) *Service {
+	if carInfoConcurrency <= 0 {
+		carInfoConcurrency = 1
+	}
+
 	return &Service{
-		carRepository:     carRepository,
-		carInfoRepository: carInfoRepository,
-		ownerRepository:   ownerRepository,
+		carRepository:       carRepository,
+		carInfoRepository:   carInfoRepository,
+		ownerRepository:     ownerRepository,
+		ownershipRepository: ownershipRepository,
+		auditRepository:     auditRepository,
+		transactor:          transactor,
+		carInfoConcurrency:  carInfoConcurrency,
+		ifMatchRequired:     ifMatchRequired,
 	}
 }
 
-func (s *Service) Index(cmd *command.CarIndex) (*[]model.Car, error) {
+func (s *Service) Index(ctx context.Context, cmd *command.CarIndex) (*model.CarList, error) {
 	const op = "service.car.Index"
 	log := app_log.Logger().With(
 		slog.String("op", op),
@@ -37,12 +65,83 @@ func (s *Service) Index(cmd *command.CarIndex) (*[]model.Car, error) {
 	log.Info("searching cars")
 
 	qry := query.CarList{
-		RegNum:       cmd.RegNum,
-		Mark:         cmd.Mark,
-		Model:        cmd.Model,
-		Year:         cmd.Year,
-		OwnerName:    cmd.OwnerName,
-		OwnerSurname: cmd.OwnerSurname,
+		RegNum:          cmd.RegNum,
+		RegNumPrefix:    cmd.RegNumPrefix,
+		Marks:           cmd.Marks,
+		MarkMatch:       query.MatchContains,
+		Model:           cmd.Model,
+		Year:            cmd.Year,
+		YearFrom:        cmd.YearFrom,
+		YearTo:          cmd.YearTo,
+		OwnerID:         cmd.OwnerID,
+		OwnerName:       cmd.OwnerName,
+		OwnerSurname:    cmd.OwnerSurname,
+		OwnerPatronymic: cmd.OwnerPatronymic,
+		OwnerMatch:      query.MatchContains,
+		HasPatronymic:   cmd.HasPatronymic,
+		IncludeDeleted:  cmd.IncludeDeleted != nil && *cmd.IncludeDeleted,
+		OnlyDeleted:     cmd.OnlyDeleted != nil && *cmd.OnlyDeleted,
+	}
+	if cmd.OwnedAt != nil && *cmd.OwnedAt != "" {
+		ownedAt, err := app_time.ParseDate(*cmd.OwnedAt)
+		if err != nil {
+			log.Error("failed to parse owned at", slog.String("error", err.Error()))
+			return nil, err
+		}
+		qry.OwnedAt = &ownedAt
+	}
+	if cmd.CreatedFrom != nil && *cmd.CreatedFrom != "" {
+		createdFrom, err := app_time.ParseDate(*cmd.CreatedFrom)
+		if err != nil {
+			log.Error("failed to parse created from", slog.String("error", err.Error()))
+			return nil, err
+		}
+		qry.CreatedFrom = &createdFrom
+	}
+	if cmd.CreatedTo != nil && *cmd.CreatedTo != "" {
+		createdTo, err := app_time.ParseDate(*cmd.CreatedTo)
+		if err != nil {
+			log.Error("failed to parse created to", slog.String("error", err.Error()))
+			return nil, err
+		}
+		qry.CreatedTo = &createdTo
+	}
+	if cmd.UpdatedSince != nil && *cmd.UpdatedSince != "" {
+		updatedSince, err := app_time.ParseDate(*cmd.UpdatedSince)
+		if err != nil {
+			log.Error("failed to parse updated since", slog.String("error", err.Error()))
+			return nil, err
+		}
+		qry.UpdatedSince = &updatedSince
+	}
+	// a single mark is found within the car mark like the model is, several
+	// marks are a list of exact marks
+	if len(cmd.Marks) > 1 {
+		qry.MarkMatch = query.MatchExact
+	}
+	if cmd.MarkMatch != nil && *cmd.MarkMatch != "" {
+		switch *cmd.MarkMatch {
+		case query.MatchExact, query.MatchPrefix, query.MatchContains:
+			qry.MarkMatch = *cmd.MarkMatch
+		default:
+			err := fmt.Errorf("%w: %s - %s", app_error.ErrInvalidArgument, "unknown mark match", *cmd.MarkMatch)
+			log.Error("failed to parse mark match", slog.String("error", err.Error()))
+			return nil, err
+		}
+	}
+	if cmd.OwnerMatch != nil && *cmd.OwnerMatch != "" {
+		switch *cmd.OwnerMatch {
+		case query.MatchExact, query.MatchPrefix, query.MatchContains:
+			qry.OwnerMatch = *cmd.OwnerMatch
+		default:
+			err := fmt.Errorf("%w: %s - %s", app_error.ErrInvalidArgument, "unknown owner match", *cmd.OwnerMatch)
+			log.Error("failed to parse owner match", slog.String("error", err.Error()))
+			return nil, err
+		}
+	}
+	if cmd.Search != nil && strings.TrimSpace(*cmd.Search) != "" {
+		search := strings.TrimSpace(*cmd.Search)
+		qry.Search = &search
 	}
 	if cmd.Page == nil || *cmd.Page <= 0 {
 		qry.Page = 1
@@ -54,23 +153,111 @@ func (s *Service) Index(cmd *command.CarIndex) (*[]model.Car, error) {
 	} else {
 		qry.Count = *cmd.Count
 	}
-	if cmd.Order == nil || *cmd.Order == "" || (*cmd.Order != "asc" && *cmd.Order != "desc") {
-		qry.Order = "desc"
-	} else {
-		qry.Order = *cmd.Order
+	order := "desc"
+	if cmd.Order != nil && *cmd.Order == "asc" {
+		order = "asc"
+	}
+	sort := ""
+	if cmd.Sort != nil {
+		sort = *cmd.Sort
+	}
+	var err error
+	if qry.Search != nil && strings.TrimSpace(sort) == "" && cmd.Cursor == nil {
+		sort = "-" + query.CarSortRelevance
 	}
-	cars, err := s.carRepository.List(&qry)
+	qry.Sort, err = parseSort(sort, order)
+	if err != nil {
+		log.Error("failed to parse sort", slog.String("error", err.Error()))
+		return nil, err
+	}
+	if sortsByRelevance(qry.Sort) && qry.Search == nil {
+		err = fmt.Errorf("%w: %s", app_error.ErrInvalidArgument, "relevance sort requires q")
+		log.Error("failed to parse sort", slog.String("error", err.Error()))
+		return nil, err
+	}
+	if cmd.Cursor != nil {
+		return s.scroll(ctx, &qry, *cmd.Cursor)
+	}
+	cars, total, err := s.carRepository.List(ctx, &qry)
 	if err != nil {
 		log.Error("failed to search cars", slog.String("error", err.Error()))
 		return nil, err
 	}
+	pagination := model.NewPagination(qry.Page, qry.Count, total)
+	carList := model.CarList{
+		Items:      *cars,
+		Pagination: &pagination,
+	}
+
+	log.Debug("searched cars", slog.Any("carList", carList))
+
+	return &carList, nil
+}
+
+func (s *Service) scroll(ctx context.Context, qry *query.CarList, cursor string) (*model.CarList, error) {
+	const op = "service.car.scroll"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("qry", qry),
+		slog.String("cursor", cursor),
+	)
+
+	log.Info("scrolling cars")
+
+	if sortsByRelevance(qry.Sort) {
+		err := fmt.Errorf("%w: %s", app_error.ErrInvalidArgument, "relevance sort does not support cursor")
+		log.Error("failed to scroll cars", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	var err error
+	qry.Cursor, err = decodeCursor(cursor, len(qry.Sort))
+	if err != nil {
+		log.Error("failed to decode cursor", slog.String("error", err.Error()))
+		return nil, err
+	}
+	cars, next, err := s.carRepository.Scroll(ctx, qry)
+	if err != nil {
+		log.Error("failed to scroll cars", slog.String("error", err.Error()))
+		return nil, err
+	}
+	nextCursor, err := encodeCursor(next)
+	if err != nil {
+		log.Error("failed to encode cursor", slog.String("error", err.Error()))
+		return nil, err
+	}
+	carList := model.CarList{
+		Items:  *cars,
+		Cursor: &model.Cursor{Count: qry.Count, NextCursor: nextCursor},
+	}
 
-	log.Debug("searched cars", slog.Any("cars", cars))
+	log.Debug("scrolled cars", slog.Any("carList", carList))
 
-	return cars, nil
+	return &carList, nil
 }
 
-func (s *Service) Store(cmd *command.CarStore) (*[]model.Car, error) {
+func (s *Service) Show(ctx context.Context, cmd *command.CarShow) (*model.Car, error) {
+	const op = "service.car.Show"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("searching car")
+
+	qry := query.CarGet{ID: cmd.ID}
+	car, err := s.carRepository.Get(ctx, &qry)
+	if err != nil {
+		log.Error("failed to search car", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Debug("searched car", slog.Any("car", car))
+
+	return car, nil
+}
+
+func (s *Service) Store(ctx context.Context, cmd *command.CarStore) (*[]model.Car, error) {
 	const op = "service.car.Store"
 	log := app_log.Logger().With(
 		slog.String("op", op),
@@ -79,45 +266,346 @@ func (s *Service) Store(cmd *command.CarStore) (*[]model.Car, error) {
 
 	log.Info("creating cars")
 
+	stored, err := s.stored(ctx, cmd.RegNums)
+	if err != nil {
+		log.Error("failed to search stored cars", slog.String("error", err.Error()))
+		return nil, err
+	}
+	if cmd.OnConflict == command.CarStoreOnConflictError {
+		for _, regNum := range cmd.RegNums {
+			if stored[regNum] != nil {
+				err = fmt.Errorf("%w: %s - %s", app_error.ErrConflict, "reg number is taken", regNum)
+				log.Error("failed to create cars", slog.String("error", err.Error()))
+				return nil, err
+			}
+		}
+	}
+	fresh := cmd.OnConflict == command.CarStoreOnConflictRefresh
+	carInfos, _, err := s.getCarInfos(ctx, lookups(cmd.RegNums, stored, cmd.OnConflict), true, fresh)
+	if err != nil {
+		log.Error("failed to get car info", slog.String("error", err.Error()))
+		return nil, err
+	}
 	cars := make([]model.Car, len(cmd.RegNums))
+	first := make(map[string]int, len(cmd.RegNums))
+	err = s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		for i, regNum := range cmd.RegNums {
+			// a regNum repeated in the batch is the car stored for it first
+			if j, ok := first[regNum]; ok {
+				cars[i] = cars[j]
+				continue
+			}
+			first[regNum] = i
+			car, _, err := s.store(ctx, regNum, stored[regNum], carInfos[regNum], cmd.OnConflict)
+			if err != nil {
+				return err
+			}
+			cars[i] = *car
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to create cars", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Debug("created cars", slog.Any("cars", cars))
+
+	return &cars, nil
+}
+
+// StorePartial stores every regNum on its own and reports the outcome per
+// item instead of failing the whole batch.
+func (s *Service) StorePartial(ctx context.Context, cmd *command.CarStore) (*[]model.CarStoreResult, error) {
+	const op = "service.car.StorePartial"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("creating cars")
+
+	stored, err := s.stored(ctx, cmd.RegNums)
+	if err != nil {
+		log.Error("failed to search stored cars", slog.String("error", err.Error()))
+		return nil, err
+	}
+	fresh := cmd.OnConflict == command.CarStoreOnConflictRefresh
+	carInfos, errs, err := s.getCarInfos(ctx, lookups(cmd.RegNums, stored, cmd.OnConflict), false, fresh)
+	if err != nil {
+		log.Error("failed to get car info", slog.String("error", err.Error()))
+		return nil, err
+	}
+	results := make([]model.CarStoreResult, len(cmd.RegNums))
+	first := make(map[string]int, len(cmd.RegNums))
 	for i, regNum := range cmd.RegNums {
-		qryCarInfo := query.CarInfo{RegNum: regNum}
-		carInfo, err := s.carInfoRepository.GetCarInfo(&qryCarInfo)
+		// a regNum repeated in the batch shares the outcome of its first item
+		if j, ok := first[regNum]; ok {
+			results[i] = results[j]
+			continue
+		}
+		first[regNum] = i
+		results[i].RegNum = regNum
+		err = errs[regNum]
+		if err == nil {
+			results[i].Car, results[i].Status, err = s.store(ctx, regNum, stored[regNum], carInfos[regNum], cmd.OnConflict)
+		}
 		if err != nil {
-			log.Error("failed to get car info", slog.String("error", err.Error()))
-			return nil, err
+			log.Error("failed to create car", slog.String("regNum", regNum), slog.String("error", err.Error()))
+			code, message := app_error.Code(err), err.Error()
+			results[i].Status = model.CarStoreStatusFailed
+			results[i].Error = &code
+			results[i].Message = &message
+		}
+	}
+
+	log.Debug("created cars", slog.Any("results", results))
+
+	return &results, nil
+}
+
+// stored returns the cars already stored under regNums by reg number.
+func (s *Service) stored(ctx context.Context, regNums []string) (map[string]*model.Car, error) {
+	qry := query.CarListByRegNums{RegNums: regNums}
+	cars, err := s.carRepository.ListByRegNums(ctx, &qry)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]*model.Car, len(*cars))
+	for i := range *cars {
+		stored[(*cars)[i].RegNum] = &(*cars)[i]
+	}
+
+	return stored, nil
+}
+
+// lookups returns the distinct regNums that need their car info looked up:
+// the new ones, and the stored ones too when they are to be refreshed.
+func lookups(regNums []string, stored map[string]*model.Car, onConflict string) []string {
+	lookups := make([]string, 0, len(regNums))
+	seen := make(map[string]bool, len(regNums))
+	for _, regNum := range regNums {
+		if seen[regNum] {
+			continue
 		}
+		seen[regNum] = true
+		if stored[regNum] == nil || onConflict == command.CarStoreOnConflictRefresh {
+			lookups = append(lookups, regNum)
+		}
+	}
+
+	return lookups
+}
+
+// store creates the car of regNum or, when current is already stored under
+// it, resolves the conflict as onConflict says. It returns the car and the
+// store status.
+func (s *Service) store(
+	ctx context.Context,
+	regNum string,
+	current *model.Car,
+	carInfo *model.CarInfo,
+	onConflict string,
+) (*model.Car, string, error) {
+	if current == nil {
+		car, err := s.create(ctx, regNum, carInfo)
+		return car, model.CarStoreStatusCreated, err
+	}
+
+	switch onConflict {
+	case command.CarStoreOnConflictSkip:
+		return current, model.CarStoreStatusSkipped, nil
+	case command.CarStoreOnConflictRefresh:
+		car, err := s.refresh(ctx, current, carInfo)
+		return car, model.CarStoreStatusRefreshed, err
+	default:
+		return nil, "", fmt.Errorf("%w: %s - %s", app_error.ErrConflict, "reg number is taken", regNum)
+	}
+}
+
+// create stores the owner and the car of a single regNum atomically.
+func (s *Service) create(ctx context.Context, regNum string, carInfo *model.CarInfo) (*model.Car, error) {
+	const op = "service.car.create"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.String("regNum", regNum),
+	)
+
+	if carInfo.Owner == nil {
+		err := fmt.Errorf("%w: %s - %s", app_error.ErrNotFound, "car info has no owner", regNum)
+		log.Error("failed to create car", slog.String("error", err.Error()))
+		return nil, err
+	}
+	var car *model.Car
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
 		qryPeopleCreate := query.PeopleCreate{
 			Name:       carInfo.Owner.Name,
 			Surname:    carInfo.Owner.Surname,
 			Patronymic: carInfo.Owner.Patronymic,
+			ExternalID: carInfo.Owner.ExternalID,
 		}
-		people, err := s.ownerRepository.Create(&qryPeopleCreate)
+		people, err := s.ownerRepository.FindOrCreate(ctx, &qryPeopleCreate)
 		if err != nil {
-			log.Error("failed to create people", slog.String("error", err.Error()))
-			return nil, err
+			log.Error("failed to find or create people", slog.String("error", err.Error()))
+			return err
 		}
 		qryCarCreate := query.CarCreate{
 			RegNum:  regNum,
 			Mark:    carInfo.Mark,
 			Model:   carInfo.Model,
 			Year:    carInfo.Year,
+			Sources: carInfo.Sources,
 			OwnerID: people.ID,
 		}
-		car, err := s.carRepository.Create(&qryCarCreate)
+		car, err = s.carRepository.Create(ctx, &qryCarCreate)
 		if err != nil {
 			log.Error("failed to create car", slog.String("error", err.Error()))
-			return nil, err
+			return err
+		}
+		qryOwnershipOpen := query.CarOwnershipOpen{
+			CarID:     car.ID,
+			OwnerID:   people.ID,
+			OwnedFrom: time.Now(),
 		}
-		cars[i] = *car
+		if _, err = s.ownershipRepository.Open(ctx, &qryOwnershipOpen); err != nil {
+			log.Error("failed to open car ownership", slog.String("error", err.Error()))
+			return err
+		}
+		if err = s.audit(ctx, model.AuditActionCreate, car.ID, nil, car); err != nil {
+			log.Error("failed to audit car", slog.String("error", err.Error()))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	log.Debug("created cars", slog.Any("cars", cars))
+	return car, nil
+}
 
-	return &cars, nil
+// refresh updates the mark, model, year and owner of a stored car from its
+// new car info, handing the car over when the owner differs.
+func (s *Service) refresh(ctx context.Context, current *model.Car, carInfo *model.CarInfo) (*model.Car, error) {
+	const op = "service.car.refresh"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.String("regNum", current.RegNum),
+	)
+
+	if carInfo.Owner == nil {
+		err := fmt.Errorf("%w: %s - %s", app_error.ErrNotFound, "car info has no owner", current.RegNum)
+		log.Error("failed to refresh car", slog.String("error", err.Error()))
+		return nil, err
+	}
+	var car *model.Car
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		qryPeopleCreate := query.PeopleCreate{
+			Name:       carInfo.Owner.Name,
+			Surname:    carInfo.Owner.Surname,
+			Patronymic: carInfo.Owner.Patronymic,
+			ExternalID: carInfo.Owner.ExternalID,
+		}
+		people, err := s.ownerRepository.FindOrCreate(ctx, &qryPeopleCreate)
+		if err != nil {
+			log.Error("failed to find or create people", slog.String("error", err.Error()))
+			return err
+		}
+		if people.ID != current.OwnerID {
+			now := time.Now()
+			qryOwnershipClose := query.CarOwnershipClose{CarID: current.ID, OwnedTo: now}
+			if err = s.ownershipRepository.Close(ctx, &qryOwnershipClose); err != nil {
+				log.Error("failed to close car ownership", slog.String("error", err.Error()))
+				return err
+			}
+			qryOwnershipOpen := query.CarOwnershipOpen{CarID: current.ID, OwnerID: people.ID, OwnedFrom: now}
+			if _, err = s.ownershipRepository.Open(ctx, &qryOwnershipOpen); err != nil {
+				log.Error("failed to open car ownership", slog.String("error", err.Error()))
+				return err
+			}
+		}
+		qryCarUpdate := query.CarUpdate{
+			ID:      int(current.ID),
+			Mark:    &carInfo.Mark,
+			Model:   &carInfo.Model,
+			Year:    carInfo.Year,
+			Sources: carInfo.Sources,
+			OwnerID: &people.ID,
+			Version: &current.Version,
+		}
+		car, err = s.carRepository.Update(ctx, &qryCarUpdate)
+		if err != nil {
+			log.Error("failed to update car", slog.String("error", err.Error()))
+			return err
+		}
+		if err = s.audit(ctx, model.AuditActionUpdate, car.ID, current, car); err != nil {
+			log.Error("failed to audit car", slog.String("error", err.Error()))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return car, nil
+}
+
+// getCarInfos looks up car info for every regNum using at most
+// carInfoConcurrency parallel requests. The results are keyed by regNum.
+// With failFast the first failure cancels the lookups still in
+// flight and is returned; otherwise failures are reported per regNum. With
+// fresh the cached car infos are skipped.
+func (s *Service) getCarInfos(
+	ctx context.Context,
+	regNums []string,
+	failFast bool,
+	fresh bool,
+) (map[string]*model.CarInfo, map[string]error, error) {
+	carInfos := make([]*model.CarInfo, len(regNums))
+	errs := make([]error, len(regNums))
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(s.carInfoConcurrency)
+	for i, regNum := range regNums {
+		if groupCtx.Err() != nil {
+			break
+		}
+		i, regNum := i, regNum
+		group.Go(func() error {
+			qry := query.CarInfo{RegNum: regNum, Fresh: fresh}
+			carInfo, err := s.carInfoRepository.GetCarInfo(groupCtx, &qry)
+			if err != nil {
+				errs[i] = err
+				if failFast {
+					return err
+				}
+				return nil
+			}
+			carInfos[i] = carInfo
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	carInfosByRegNum := make(map[string]*model.CarInfo, len(regNums))
+	errsByRegNum := make(map[string]error, len(regNums))
+	for i, regNum := range regNums {
+		carInfosByRegNum[regNum] = carInfos[i]
+		if errs[i] != nil {
+			errsByRegNum[regNum] = errs[i]
+		}
+	}
+
+	return carInfosByRegNum, errsByRegNum, nil
 }
 
-func (s *Service) Update(cmd *command.CarUpdate) (*model.Car, error) {
+func (s *Service) Update(ctx context.Context, cmd *command.CarUpdate) (*model.Car, error) {
 	const op = "service.car.Update"
 	log := app_log.Logger().With(
 		slog.String("op", op),
@@ -126,14 +614,40 @@ func (s *Service) Update(cmd *command.CarUpdate) (*model.Car, error) {
 
 	log.Info("updating car")
 
-	qry := query.CarUpdate{
-		ID:     cmd.ID,
-		RegNum: cmd.RegNum,
-		Mark:   cmd.Mark,
-		Model:  cmd.Model,
-		Year:   cmd.Year,
+	if cmd.IfMatch == nil && s.ifMatchRequired {
+		err := fmt.Errorf("%w: %s - %d", app_error.ErrPreconditionRequired, "If-Match is required to update car", cmd.ID)
+		log.Error("failed to update car", slog.String("error", err.Error()))
+		return nil, err
+	}
+	var versions []uint
+	if cmd.IfMatch != nil {
+		versions = parseIfMatch(*cmd.IfMatch)
 	}
-	car, err := s.carRepository.Update(&qry)
+
+	var car *model.Car
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		qryGet := query.CarGet{ID: cmd.ID}
+		before, err := s.carRepository.Get(ctx, &qryGet)
+		if err != nil {
+			return err
+		}
+		if versions != nil && !slices.Contains(versions, before.Version) {
+			return fmt.Errorf("%w: %s - %d", app_error.ErrPreconditionFailed, "car version is", before.Version)
+		}
+		qry := query.CarUpdate{
+			ID:      cmd.ID,
+			RegNum:  cmd.RegNum,
+			Mark:    cmd.Mark,
+			Model:   cmd.Model,
+			Year:    cmd.Year,
+			Version: &before.Version,
+		}
+		car, err = s.carRepository.Update(ctx, &qry)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, model.AuditActionUpdate, car.ID, before, car)
+	})
 	if err != nil {
 		log.Error("failed to update car", slog.String("error", err.Error()))
 		return nil, err
@@ -144,7 +658,7 @@ func (s *Service) Update(cmd *command.CarUpdate) (*model.Car, error) {
 	return car, nil
 }
 
-func (s *Service) Delete(cmd *command.CarDelete) error {
+func (s *Service) Delete(ctx context.Context, cmd *command.CarDelete) error {
 	const op = "service.car.Delete"
 	log := app_log.Logger().With(
 		slog.String("op", op),
@@ -153,8 +667,18 @@ func (s *Service) Delete(cmd *command.CarDelete) error {
 
 	log.Info("deleting car")
 
-	qry := query.CarDelete{ID: cmd.ID}
-	err := s.carRepository.Delete(&qry)
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		qryGet := query.CarGet{ID: cmd.ID}
+		before, err := s.carRepository.Get(ctx, &qryGet)
+		if err != nil {
+			return err
+		}
+		qry := query.CarDelete{ID: cmd.ID}
+		if err = s.carRepository.Delete(ctx, &qry); err != nil {
+			return err
+		}
+		return s.audit(ctx, model.AuditActionDelete, before.ID, before, nil)
+	})
 	if err != nil {
 		log.Error("failed to delete car", slog.String("error", err.Error()))
 		return err
@@ -164,3 +688,223 @@ func (s *Service) Delete(cmd *command.CarDelete) error {
 
 	return nil
 }
+
+func (s *Service) Restore(ctx context.Context, cmd *command.CarRestore) (*model.Car, error) {
+	const op = "service.car.Restore"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("restoring car")
+
+	var car *model.Car
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		qry := query.CarRestore{ID: cmd.ID}
+		var err error
+		car, err = s.carRepository.Restore(ctx, &qry)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, model.AuditActionRestore, car.ID, nil, car)
+	})
+	if err != nil {
+		log.Error("failed to restore car", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Debug("restored car", slog.Any("car", car))
+
+	return car, nil
+}
+
+// Transfer hands the car over to another owner: the current ownership is
+// closed and a new one is opened at the transfer date, now by default.
+func (s *Service) Transfer(ctx context.Context, cmd *command.CarTransfer) (*model.Car, error) {
+	const op = "service.car.Transfer"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("transferring car")
+
+	date := time.Now()
+	if cmd.Date != nil {
+		// the owner changes right away, so the handover can't be scheduled
+		if cmd.Date.After(date) {
+			err := fmt.Errorf("%w: %s - %s", app_error.ErrInvalidArgument, "transfer date is in the future", cmd.Date.Format(time.RFC3339))
+			log.Error("failed to transfer car", slog.String("error", err.Error()))
+			return nil, err
+		}
+		date = *cmd.Date
+	}
+
+	var car *model.Car
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		qryCarGet := query.CarGet{ID: cmd.ID}
+		current, err := s.carRepository.Get(ctx, &qryCarGet)
+		if err != nil {
+			return err
+		}
+		if current.OwnerID == cmd.OwnerID {
+			return fmt.Errorf("%w: %s - %d", app_error.ErrInvalidArgument, "car already belongs to owner", cmd.OwnerID)
+		}
+		qryPeopleGet := query.PeopleGet{ID: int(cmd.OwnerID)}
+		if _, err = s.ownerRepository.Get(ctx, &qryPeopleGet); err != nil {
+			return err
+		}
+
+		qryOwnershipClose := query.CarOwnershipClose{CarID: current.ID, OwnedTo: date}
+		if err = s.ownershipRepository.Close(ctx, &qryOwnershipClose); err != nil {
+			return err
+		}
+		qryOwnershipOpen := query.CarOwnershipOpen{CarID: current.ID, OwnerID: cmd.OwnerID, OwnedFrom: date}
+		if _, err = s.ownershipRepository.Open(ctx, &qryOwnershipOpen); err != nil {
+			return err
+		}
+		// the version fails a transfer racing another one, which would
+		// otherwise leave the car with two open ownerships
+		qryCarUpdate := query.CarUpdate{ID: cmd.ID, OwnerID: &cmd.OwnerID, Version: &current.Version}
+		car, err = s.carRepository.Update(ctx, &qryCarUpdate)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, model.AuditActionTransfer, car.ID, current, car)
+	})
+	if err != nil {
+		log.Error("failed to transfer car", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Debug("transferred car", slog.Any("car", car))
+
+	return car, nil
+}
+
+func (s *Service) Owners(ctx context.Context, cmd *command.CarOwners) (*[]model.CarOwnership, error) {
+	const op = "service.car.Owners"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("searching car owners")
+
+	qryCarGet := query.CarGet{ID: cmd.ID}
+	car, err := s.carRepository.Get(ctx, &qryCarGet)
+	if err != nil {
+		log.Error("failed to search car", slog.String("error", err.Error()))
+		return nil, err
+	}
+	qry := query.CarOwnershipList{CarID: car.ID}
+	ownerships, err := s.ownershipRepository.List(ctx, &qry)
+	if err != nil {
+		log.Error("failed to search car ownerships", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Debug("searched car owners", slog.Any("ownerships", ownerships))
+
+	return ownerships, nil
+}
+
+// Owner returns the ownership of the car covering cmd.At, now by default.
+func (s *Service) Owner(ctx context.Context, cmd *command.CarOwner) (*model.CarOwnership, error) {
+	const op = "service.car.Owner"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("searching car owner")
+
+	at := time.Now()
+	if cmd.At != nil && *cmd.At != "" {
+		var err error
+		at, err = app_time.ParseDate(*cmd.At)
+		if err != nil {
+			log.Error("failed to parse at", slog.String("error", err.Error()))
+			return nil, err
+		}
+	}
+	qryCarGet := query.CarGet{ID: cmd.ID}
+	car, err := s.carRepository.Get(ctx, &qryCarGet)
+	if err != nil {
+		log.Error("failed to search car", slog.String("error", err.Error()))
+		return nil, err
+	}
+	qry := query.CarOwnershipAt{CarID: car.ID, At: at}
+	ownership, err := s.ownershipRepository.At(ctx, &qry)
+	if err != nil {
+		log.Error("failed to search car ownership", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Debug("searched car owner", slog.Any("ownership", ownership))
+
+	return ownership, nil
+}
+
+// History lists the recorded changes of a car, the latest first. The car
+// may have been deleted since.
+func (s *Service) History(ctx context.Context, cmd *command.CarHistory) (*model.AuditLogList, error) {
+	const op = "service.car.History"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("searching car history")
+
+	entityType := model.AuditEntityCar
+	entityID := uint(cmd.ID)
+	qry := query.AuditLogList{
+		EntityType: &entityType,
+		EntityID:   &entityID,
+	}
+	if cmd.Page == nil || *cmd.Page <= 0 {
+		qry.Page = 1
+	} else {
+		qry.Page = *cmd.Page
+	}
+	if cmd.Count == nil || *cmd.Count <= 0 {
+		qry.Count = 10
+	} else {
+		qry.Count = *cmd.Count
+	}
+	auditLogs, total, err := s.auditRepository.List(ctx, &qry)
+	if err != nil {
+		log.Error("failed to search car history", slog.String("error", err.Error()))
+		return nil, err
+	}
+	auditLogList := model.AuditLogList{
+		Items:      *auditLogs,
+		Pagination: model.NewPagination(qry.Page, qry.Count, total),
+	}
+
+	log.Debug("searched car history", slog.Any("auditLogList", auditLogList))
+
+	return &auditLogList, nil
+}
+
+// audit records the change of a car in the transaction of ctx. before and
+// after are nil when the car did not exist on that side of the change.
+func (s *Service) audit(ctx context.Context, action string, id uint, before, after *model.Car) error {
+	qry := query.AuditLogCreate{
+		EntityType: model.AuditEntityCar,
+		EntityID:   id,
+		Action:     action,
+		RequestID:  app_audit.RequestID(ctx),
+		Actor:      app_audit.Actor(ctx),
+	}
+	if before != nil {
+		qry.Before = before
+	}
+	if after != nil {
+		qry.After = after
+	}
+	_, err := s.auditRepository.Create(ctx, &qry)
+
+	return err
+}
@@ -0,0 +1,61 @@
+package car
+
+import (
+	"fmt"
+	"strings"
+
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/dto/query"
+)
+
+var sortFields = map[string]bool{
+	query.CarSortID:              true,
+	query.CarSortRegNum:          true,
+	query.CarSortMark:            true,
+	query.CarSortModel:           true,
+	query.CarSortYear:            true,
+	query.CarSortOwnerName:       true,
+	query.CarSortOwnerSurname:    true,
+	query.CarSortOwnerPatronymic: true,
+	query.CarSortRelevance:       true,
+}
+
+// parseSort turns "-year,mark,owner.surname" into sort keys. The id is
+// appended in the given order unless requested explicitly, so that the
+// resulting ordering is always total.
+func parseSort(sort string, order string) ([]query.CarSort, error) {
+	sorts := make([]query.CarSort, 0)
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field, desc := strings.CutPrefix(part, "-")
+		if !sortFields[field] {
+			return nil, fmt.Errorf("%w: %s - %s", app_error.ErrInvalidArgument, "unknown sort field", field)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("%w: %s - %s", app_error.ErrInvalidArgument, "duplicate sort field", field)
+		}
+		seen[field] = true
+		sorts = append(sorts, query.CarSort{Field: field, Desc: desc})
+	}
+
+	if !seen[query.CarSortID] {
+		sorts = append(sorts, query.CarSort{Field: query.CarSortID, Desc: order == "desc"})
+	}
+
+	return sorts, nil
+}
+
+func sortsByRelevance(sorts []query.CarSort) bool {
+	for _, sort := range sorts {
+		if sort.Field == query.CarSortRelevance {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,7 @@
+package health
+
+import "effective_mobile_2/internal/dto/model"
+
+type carInfoBreakers interface {
+	Breakers() map[string]model.CircuitBreaker
+}
@@ -0,0 +1,40 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/dto/model"
+)
+
+type Service struct {
+	carInfoBreakers carInfoBreakers
+}
+
+func New(carInfoBreakers carInfoBreakers) *Service {
+	return &Service{carInfoBreakers: carInfoBreakers}
+}
+
+// Show reports the service as degraded while any car info provider is
+// cut off by its circuit breaker.
+func (s *Service) Show(ctx context.Context) (*model.Health, error) {
+	const op = "service.health.Show"
+	log := app_log.Logger().With(slog.String("op", op))
+
+	log.Info("checking health")
+
+	health := model.Health{
+		Status:  model.HealthStatusOk,
+		CarInfo: s.carInfoBreakers.Breakers(),
+	}
+	for _, breaker := range health.CarInfo {
+		if breaker.State != model.CircuitBreakerClosed {
+			health.Status = model.HealthStatusDegraded
+		}
+	}
+
+	log.Debug("checked health", slog.Any("health", health))
+
+	return &health, nil
+}
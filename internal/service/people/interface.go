@@ -0,0 +1,34 @@
+package people
+
+import (
+	"context"
+
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+)
+
+type peopleRepository interface {
+	List(ctx context.Context, qry *query.PeopleList) (*[]model.People, error)
+	Get(ctx context.Context, qry *query.PeopleGet) (*model.People, error)
+	Create(ctx context.Context, qry *query.PeopleCreate) (*model.People, error)
+	Update(ctx context.Context, qry *query.PeopleUpdate) (*model.People, error)
+	Delete(ctx context.Context, qry *query.PeopleDelete) error
+	Duplicates(ctx context.Context, qry *query.PeopleDuplicates) (*[]model.PeopleDuplicate, error)
+}
+
+type carRepository interface {
+	ListByOwnerIDs(ctx context.Context, qry *query.CarListByOwnerIDs) (*[]model.Car, error)
+	ReassignOwner(ctx context.Context, qry *query.CarReassignOwner) (*[]model.Car, error)
+}
+
+type ownershipRepository interface {
+	ReassignOwner(ctx context.Context, qry *query.CarOwnershipReassignOwner) (int64, error)
+}
+
+type auditRepository interface {
+	Create(ctx context.Context, qry *query.AuditLogCreate) (*model.AuditLog, error)
+}
+
+type transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,360 @@
+package people
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"effective_mobile_2/internal/app_audit"
+	"effective_mobile_2/internal/app_error"
+	"effective_mobile_2/internal/app_log"
+	"effective_mobile_2/internal/dto/command"
+	"effective_mobile_2/internal/dto/model"
+	"effective_mobile_2/internal/dto/query"
+)
+
+type Service struct {
+	peopleRepository    peopleRepository
+	carRepository       carRepository
+	ownershipRepository ownershipRepository
+	auditRepository     auditRepository
+	transactor          transactor
+}
+
+func New(
+	peopleRepository peopleRepository,
+	carRepository carRepository,
+	ownershipRepository ownershipRepository,
+	auditRepository auditRepository,
+	transactor transactor,
+) *Service {
+	return &Service{
+		peopleRepository:    peopleRepository,
+		carRepository:       carRepository,
+		ownershipRepository: ownershipRepository,
+		auditRepository:     auditRepository,
+		transactor:          transactor,
+	}
+}
+
+// errDryRun rolls back the merge transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+func (s *Service) Index(ctx context.Context, cmd *command.PeopleIndex) (*[]model.People, error) {
+	const op = "service.people.Index"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("searching peoples")
+
+	qry := query.PeopleList{
+		Name:       cmd.Name,
+		Surname:    cmd.Surname,
+		Patronymic: cmd.Patronymic,
+	}
+	if cmd.Page == nil || *cmd.Page <= 0 {
+		qry.Page = 1
+	} else {
+		qry.Page = *cmd.Page
+	}
+	if cmd.Count == nil || *cmd.Count <= 0 {
+		qry.Count = 10
+	} else {
+		qry.Count = *cmd.Count
+	}
+	if cmd.Order == nil || *cmd.Order == "" || (*cmd.Order != "asc" && *cmd.Order != "desc") {
+		qry.Order = "desc"
+	} else {
+		qry.Order = *cmd.Order
+	}
+	peoples, err := s.peopleRepository.List(ctx, &qry)
+	if err != nil {
+		log.Error("failed to search peoples", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Debug("searched peoples", slog.Any("peoples", peoples))
+
+	return peoples, nil
+}
+
+func (s *Service) Show(ctx context.Context, cmd *command.PeopleShow) (*model.People, error) {
+	const op = "service.people.Show"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("searching people")
+
+	qry := query.PeopleGet{ID: cmd.ID}
+	people, err := s.peopleRepository.Get(ctx, &qry)
+	if err != nil {
+		log.Error("failed to search people", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Debug("searched people", slog.Any("people", people))
+
+	return people, nil
+}
+
+func (s *Service) Store(ctx context.Context, cmd *command.PeopleStore) (*model.People, error) {
+	const op = "service.people.Store"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("creating people")
+
+	var people *model.People
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		qry := query.PeopleCreate{
+			Name:       cmd.Name,
+			Surname:    cmd.Surname,
+			Patronymic: cmd.Patronymic,
+		}
+		var err error
+		people, err = s.peopleRepository.Create(ctx, &qry)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, model.AuditActionCreate, people.ID, nil, people)
+	})
+	if err != nil {
+		log.Error("failed to create people", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Debug("created people", slog.Any("people", people))
+
+	return people, nil
+}
+
+func (s *Service) Update(ctx context.Context, cmd *command.PeopleUpdate) (*model.People, error) {
+	const op = "service.people.Update"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("updating people")
+
+	var people *model.People
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		qryGet := query.PeopleGet{ID: cmd.ID}
+		before, err := s.peopleRepository.Get(ctx, &qryGet)
+		if err != nil {
+			return err
+		}
+		qry := query.PeopleUpdate{
+			ID:         cmd.ID,
+			Name:       cmd.Name,
+			Surname:    cmd.Surname,
+			Patronymic: cmd.Patronymic,
+		}
+		people, err = s.peopleRepository.Update(ctx, &qry)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, model.AuditActionUpdate, people.ID, before, people)
+	})
+	if err != nil {
+		log.Error("failed to update people", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Debug("updated people", slog.Any("people", people))
+
+	return people, nil
+}
+
+func (s *Service) Delete(ctx context.Context, cmd *command.PeopleDelete) error {
+	const op = "service.people.Delete"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("deleting people")
+
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		qryGet := query.PeopleGet{ID: cmd.ID}
+		before, err := s.peopleRepository.Get(ctx, &qryGet)
+		if err != nil {
+			return err
+		}
+		qry := query.PeopleDelete{ID: cmd.ID}
+		if err = s.peopleRepository.Delete(ctx, &qry); err != nil {
+			return err
+		}
+		return s.audit(ctx, model.AuditActionDelete, before.ID, before, nil)
+	})
+	if err != nil {
+		log.Error("failed to delete people", slog.String("error", err.Error()))
+		return err
+	}
+
+	log.Debug("deleted people")
+
+	return nil
+}
+
+// Merge moves the cars of the duplicates to the target people and deletes
+// the duplicates in one transaction. A dry run does the same work but rolls
+// it back, reporting what would have happened.
+func (s *Service) Merge(ctx context.Context, cmd *command.PeopleMerge) (*model.PeopleMerge, error) {
+	const op = "service.people.Merge"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("merging peoples")
+
+	merge := model.PeopleMerge{MergedIDs: cmd.DuplicateIDs, DryRun: cmd.DryRun}
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		qryGet := query.PeopleGet{ID: cmd.ID}
+		target, err := s.peopleRepository.Get(ctx, &qryGet)
+		if err != nil {
+			return err
+		}
+		merge.Target = *target
+
+		fromOwnerIDs := make([]uint, len(cmd.DuplicateIDs))
+		for i, id := range cmd.DuplicateIDs {
+			if id == cmd.ID {
+				return fmt.Errorf("%w: %s - %d", app_error.ErrInvalidArgument, "target is listed as duplicate", id)
+			}
+			fromOwnerIDs[i] = uint(id)
+		}
+		qryCarList := query.CarListByOwnerIDs{OwnerIDs: fromOwnerIDs}
+		before, err := s.carRepository.ListByOwnerIDs(ctx, &qryCarList)
+		if err != nil {
+			return err
+		}
+		qryReassign := query.CarReassignOwner{FromOwnerIDs: fromOwnerIDs, ToOwnerID: target.ID}
+		cars, err := s.carRepository.ReassignOwner(ctx, &qryReassign)
+		if err != nil {
+			return err
+		}
+		merge.CarsReassigned = int64(len(*cars))
+		if err = s.auditCars(ctx, model.AuditActionMerge, *before, *cars); err != nil {
+			return err
+		}
+		qryOwnershipReassign := query.CarOwnershipReassignOwner{FromOwnerIDs: fromOwnerIDs, ToOwnerID: target.ID}
+		if _, err = s.ownershipRepository.ReassignOwner(ctx, &qryOwnershipReassign); err != nil {
+			return err
+		}
+		for _, id := range cmd.DuplicateIDs {
+			qryDuplicateGet := query.PeopleGet{ID: id}
+			duplicate, err := s.peopleRepository.Get(ctx, &qryDuplicateGet)
+			if err != nil {
+				return err
+			}
+			qryDelete := query.PeopleDelete{ID: id}
+			if err = s.peopleRepository.Delete(ctx, &qryDelete); err != nil {
+				return err
+			}
+			if err = s.audit(ctx, model.AuditActionMerge, duplicate.ID, duplicate, target); err != nil {
+				return err
+			}
+		}
+
+		if cmd.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		log.Error("failed to merge peoples", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Debug("merged peoples", slog.Any("merge", merge))
+
+	return &merge, nil
+}
+
+func (s *Service) Duplicates(ctx context.Context, cmd *command.PeopleDuplicates) (*[]model.PeopleDuplicate, error) {
+	const op = "service.people.Duplicates"
+	log := app_log.Logger().With(
+		slog.String("op", op),
+		slog.Any("cmd", cmd),
+	)
+
+	log.Info("searching duplicates")
+
+	var qry query.PeopleDuplicates
+	if cmd.Page == nil || *cmd.Page <= 0 {
+		qry.Page = 1
+	} else {
+		qry.Page = *cmd.Page
+	}
+	if cmd.Count == nil || *cmd.Count <= 0 {
+		qry.Count = 10
+	} else {
+		qry.Count = *cmd.Count
+	}
+	duplicates, err := s.peopleRepository.Duplicates(ctx, &qry)
+	if err != nil {
+		log.Error("failed to search duplicates", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	log.Debug("searched duplicates", slog.Any("duplicates", duplicates))
+
+	return duplicates, nil
+}
+
+// audit records the change of a people in the transaction of ctx. before
+// and after are nil when the people did not exist on that side of the change.
+func (s *Service) audit(ctx context.Context, action string, id uint, before, after *model.People) error {
+	qry := query.AuditLogCreate{
+		EntityType: model.AuditEntityPeople,
+		EntityID:   id,
+		Action:     action,
+		RequestID:  app_audit.RequestID(ctx),
+		Actor:      app_audit.Actor(ctx),
+	}
+	if before != nil {
+		qry.Before = before
+	}
+	if after != nil {
+		qry.After = after
+	}
+	_, err := s.auditRepository.Create(ctx, &qry)
+
+	return err
+}
+
+// auditCars records the change of every car of after, matched to the car
+// it was in before by ID, in the transaction of ctx.
+func (s *Service) auditCars(ctx context.Context, action string, before, after []model.Car) error {
+	cars := make(map[uint]*model.Car, len(before))
+	for i := range before {
+		cars[before[i].ID] = &before[i]
+	}
+	for i := range after {
+		qry := query.AuditLogCreate{
+			EntityType: model.AuditEntityCar,
+			EntityID:   after[i].ID,
+			Action:     action,
+			After:      &after[i],
+			RequestID:  app_audit.RequestID(ctx),
+			Actor:      app_audit.Actor(ctx),
+		}
+		if car, ok := cars[after[i].ID]; ok {
+			qry.Before = car
+		}
+		if _, err := s.auditRepository.Create(ctx, &qry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}